@@ -55,7 +55,7 @@ func runAuth(cmd *cobra.Command, args []string) error {
 
 	// Validate API key by making a test request
 	client := linear.NewClient(apiKey)
-	if err := client.ValidateAPIKey(); err != nil {
+	if err := client.ValidateAPIKey(cmd.Context()); err != nil {
 		return fmt.Errorf("invalid API key: %w", err)
 	}
 
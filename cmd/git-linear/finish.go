@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/metalgrid/git-linear/internal/branch"
+	"github.com/metalgrid/git-linear/internal/config"
+	"github.com/metalgrid/git-linear/internal/git"
+	"github.com/metalgrid/git-linear/internal/linear"
+	"github.com/spf13/cobra"
+)
+
+var finishCmd = &cobra.Command{
+	Use:   "finish",
+	Short: "Close out the current issue branch",
+	Long: `Resolve the Linear issue from the current branch, optionally push the branch,
+move the issue to the configured workflow state, switch back to the default
+branch and offer to delete the issue branch once it has been merged.`,
+	Args: cobra.NoArgs,
+	RunE: runFinish,
+}
+
+var (
+	finishPush   bool
+	finishRemote string
+	finishState  string
+	finishYes    bool
+)
+
+func init() {
+	finishCmd.Flags().BoolVar(&finishPush, "push", false, "Push the branch before switching away from it")
+	finishCmd.Flags().StringVar(&finishRemote, "remote", "", "Remote to push to (default from git config linear.remote, or origin)")
+	finishCmd.Flags().StringVar(&finishState, "state", "", "Workflow state to move the issue to, e.g. \"In Review\" (default from git config linear.finishState)")
+	finishCmd.Flags().BoolVarP(&finishYes, "yes", "y", false, "Delete the branch without asking if it has been merged")
+	rootCmd.AddCommand(finishCmd)
+}
+
+func runFinish(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if !git.IsInsideWorkTree(ctx) {
+		return fmt.Errorf("not a git repository. Run this from inside a git project")
+	}
+
+	state, err := checkRepoState(ctx)
+	if err != nil {
+		return err
+	}
+	if state.Detached {
+		return fmt.Errorf("HEAD is detached. Switch to an issue branch first")
+	}
+
+	if git.HasUncommittedChanges(ctx) {
+		return fmt.Errorf("you have uncommitted changes. Please commit or stash them before finishing the branch")
+	}
+
+	cfg, err := config.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("state") {
+		cfg.FinishState = finishState
+	}
+	if cmd.Flags().Changed("remote") {
+		cfg.Remote = finishRemote
+	}
+
+	current, err := git.GetCurrentBranch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+
+	defaultBranch, err := git.GetDefaultBranch(ctx)
+	if err != nil {
+		return err
+	}
+	if current == defaultBranch {
+		return fmt.Errorf("already on the default branch %s. Switch to an issue branch first", defaultBranch)
+	}
+
+	identifiers := branch.ParseIdentifiers(current)
+	if len(identifiers) == 0 {
+		return fmt.Errorf("branch %s does not contain a Linear issue identifier", current)
+	}
+
+	if finishPush {
+		fmt.Printf("Pushing %s to %s...\n", current, cfg.Remote)
+		if err := git.Push(ctx, cfg.Remote, current); err != nil {
+			return fmt.Errorf("failed to push %s: %w", current, err)
+		}
+	}
+
+	if cfg.FinishState != "" {
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		issue, err := findIssue(ctx, client, identifiers)
+		if err != nil {
+			return err
+		}
+
+		state, err := client.MoveIssueToState(ctx, *issue, cfg.FinishState)
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %w", issue.Identifier, err)
+		}
+		fmt.Printf("✓ Moved %s to %s\n", issue.Identifier, state.Name)
+	}
+
+	// Ctrl+C only interrupts the push, fetch and Linear calls, not git
+	// changing branches
+	gitCtx := context.WithoutCancel(ctx)
+	if err := git.SwitchBranch(gitCtx, defaultBranch); err != nil {
+		return fmt.Errorf("failed to switch to %s: %w", defaultBranch, err)
+	}
+	fmt.Printf("✓ Switched to branch: %s\n", defaultBranch)
+
+	target, err := mergedInto(ctx, cfg, current, defaultBranch)
+	if err != nil {
+		return fmt.Errorf("failed to check whether %s is merged: %w", current, err)
+	}
+	if target == "" {
+		fmt.Printf("Branch %s is not merged into %s yet; keeping it.\n", current, defaultBranch)
+		return nil
+	}
+
+	if finishYes || confirm(fmt.Sprintf("Branch %s is merged into %s. Delete it?", current, target), false) {
+		// git only deletes branches merged into HEAD without forcing it
+		if err := git.DeleteBranch(gitCtx, current, target != defaultBranch); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", current, err)
+		}
+		fmt.Printf("✓ Deleted branch: %s\n", current)
+	}
+
+	return nil
+}
+
+// mergedInto returns the branch name has been merged into: the local
+// default branch or, as that may be behind, the freshly fetched default
+// branch of the remote. It returns an empty string if it is not merged.
+func mergedInto(ctx context.Context, cfg config.Config, name, defaultBranch string) (string, error) {
+	gitCtx := context.WithoutCancel(ctx)
+	merged, err := git.IsMerged(gitCtx, name, defaultBranch)
+	if err != nil || merged {
+		return defaultBranch, err
+	}
+	if !git.RemoteExists(gitCtx, cfg.Remote) {
+		return "", nil
+	}
+
+	fmt.Printf("Fetching %s...\n", cfg.Remote)
+	base, err := git.FetchBase(ctx, cfg.Remote)
+	if err != nil {
+		fmt.Printf("⚠ Could not fetch %s, checking against the local %s only: %v\n", cfg.Remote, defaultBranch, err)
+		return "", nil
+	}
+	if merged, err = git.IsMerged(gitCtx, name, base); err != nil || !merged {
+		return "", err
+	}
+	return base, nil
+}
+
+// findIssue fetches the first of the identifiers found in a branch name that
+// is an issue. Fields of the branch template, e.g. a username like jane-2,
+// can look like identifiers too.
+func findIssue(ctx context.Context, client *linear.Client, identifiers []string) (*linear.Issue, error) {
+	var err error
+	for _, identifier := range identifiers {
+		var issue *linear.Issue
+		issue, err = client.GetIssue(ctx, identifier)
+		if err == nil {
+			return issue, nil
+		}
+		if !errors.Is(err, linear.ErrNotFound) {
+			return nil, fmt.Errorf("failed to fetch %s: %w", identifier, err)
+		}
+	}
+	return nil, fmt.Errorf("failed to fetch %s: %w", strings.Join(identifiers, " or "), err)
+}
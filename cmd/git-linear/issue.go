@@ -0,0 +1,248 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/metalgrid/git-linear/internal/branch"
+	"github.com/metalgrid/git-linear/internal/config"
+	"github.com/metalgrid/git-linear/internal/git"
+	"github.com/metalgrid/git-linear/internal/linear"
+)
+
+// runIssue creates or switches to the branch of a single issue without
+// launching the TUI
+func runIssue(ctx context.Context, client *linear.Client, cfg config.Config, username, ref string) error {
+	issue, err := client.GetIssueByIdentifier(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("failed to fetch issue: %w", err)
+	}
+	fmt.Printf("Issue: %s - %s\n", issue.Identifier, issue.Title)
+
+	// Linear's name is only used if it is a valid branch name
+	linearName := ""
+	if cfg.BranchStrategy == branch.StrategyLinear && issue.BranchName != "" &&
+		branch.Validate(issue.BranchName) == nil {
+		linearName = issue.BranchName
+	}
+	suggestion := branch.Suggest(cfg.BranchStrategy, cfg.BranchTemplate, *issue, username, linearName)
+	if branchSuffix != "" {
+		suggestion.Suffix = branchSuffix
+	}
+	name := suggestion.Name()
+
+	// Offer the branches already created for the issue, whatever their
+	// name, unless a custom suffix asks for a specific one
+	matches, err := git.FindIssueBranches(ctx, issue.Identifier)
+	if err != nil {
+		return fmt.Errorf("failed to list branches: %w", err)
+	}
+	var existing *git.Ref
+	confirmed := assumeYes
+	if branchSuffix != "" {
+		for _, ref := range matches {
+			if strings.EqualFold(ref.Branch, name) {
+				existing = &ref
+			}
+		}
+	} else if len(matches) > 0 {
+		choice := 0
+		if !assumeYes {
+			options := make([]string, 0, len(matches)+1)
+			for _, ref := range matches {
+				option := ref.String()
+				if !ref.Local() {
+					option += " (remote only, creates a local tracking branch)"
+				}
+				options = append(options, option)
+			}
+			options = append(options, fmt.Sprintf("Create a new branch %s", name))
+			choice = choose(fmt.Sprintf("Found existing branches for %s:", issue.Identifier), options, 0)
+			if choice < 0 {
+				return nil
+			}
+			confirmed = true
+		}
+		if choice < len(matches) {
+			existing = &matches[choice]
+		}
+	}
+	exists := existing != nil
+	if exists {
+		name = existing.Branch
+	}
+
+	base := ""
+	if !exists {
+		if err := branch.Validate(name); err != nil {
+			return err
+		}
+		if stackOn != "" {
+			if !git.LocalBranchExists(ctx, stackOn) {
+				return fmt.Errorf("base branch %s does not exist", stackOn)
+			}
+			base = stackOn
+		} else if base, err = resolveBase(ctx, cfg); err != nil {
+			return err
+		}
+	}
+
+	question := fmt.Sprintf("Create branch %s from %s?", name, base)
+	switch {
+	case exists && !existing.Local():
+		question = fmt.Sprintf("Branch %s only exists on %s. Create a local branch tracking it?", name, existing.Remote)
+	case exists:
+		question = fmt.Sprintf("Branch %s already exists. Switch to it?", name)
+	}
+	if !confirmed && !confirm(question, true) {
+		return nil
+	}
+
+	// Killing git half-way could leave a branch created but not checked
+	// out, or a stale index.lock, so Ctrl+C only interrupts Linear calls
+	gitCtx := context.WithoutCancel(ctx)
+
+	if exists && !existing.Local() {
+		if err := git.CreateTrackingBranch(gitCtx, *existing); err != nil {
+			return fmt.Errorf("failed to create %s from %s: %w", name, existing, err)
+		}
+		fmt.Printf("✓ Created branch %s tracking %s\n", name, existing)
+	}
+
+	// Changes to tracked files are stashed and re-applied unless the user
+	// prefers to carry them over; worktree mode leaves them alone
+	stash := false
+	if !cfg.Worktree {
+		status, err := git.GetStatus(gitCtx)
+		if err != nil {
+			return fmt.Errorf("failed to get status: %w", err)
+		}
+		if status.HasModifications() {
+			stash = assumeYes || confirm(fmt.Sprintf("You have uncommitted changes (%s). Stash them and re-apply them on %s?", status, name), true)
+		}
+	}
+
+	if stash {
+		err = withStash(gitCtx, issue.Identifier, func() error {
+			return checkoutBranch(gitCtx, cfg, name, base)
+		})
+	} else {
+		err = checkoutBranch(gitCtx, cfg, name, base)
+	}
+	if err != nil {
+		return err
+	}
+	if !exists && stackOn != "" {
+		if err := git.SetParent(gitCtx, name, stackOn); err != nil {
+			fmt.Printf("⚠ Could not record %s as the parent of %s: %v\n", stackOn, name, err)
+		}
+	}
+
+	if cfg.StartIssue {
+		state, err := client.StartIssue(ctx, *issue)
+		if err != nil {
+			fmt.Printf("⚠ Could not update %s: %v\n", issue.Identifier, err)
+		} else if state.ID != issue.State.ID {
+			fmt.Printf("✓ Moved %s to %s\n", issue.Identifier, state.Name)
+		}
+	}
+
+	return nil
+}
+
+// checkoutBranch switches to the branch, creating it from base when base is
+// not empty. In worktree mode, or when the branch is already checked out in
+// another worktree, it prints the worktree path instead of switching.
+func checkoutBranch(ctx context.Context, cfg config.Config, name, base string) error {
+	if cfg.Worktree {
+		path, _, err := git.EnsureWorktree(ctx, cfg.WorktreeLayout, name, base)
+		if err != nil {
+			return fmt.Errorf("failed to create worktree for %s: %w", name, err)
+		}
+		if base != "" {
+			setUpstream(ctx, cfg, name)
+		}
+		printWorktree(name, path)
+		return nil
+	}
+
+	if base == "" {
+		// Git refuses to check out a branch used by another worktree
+		path, found, err := git.OtherWorktree(ctx, name)
+		if err != nil {
+			return err
+		}
+		if found {
+			printWorktree(name, path)
+			return nil
+		}
+	} else {
+		if err := git.CreateBranch(ctx, name, base); err != nil {
+			return fmt.Errorf("failed to create %s: %w", name, err)
+		}
+		setUpstream(ctx, cfg, name)
+	}
+
+	if err := git.SwitchBranch(ctx, name); err != nil {
+		return fmt.Errorf("failed to switch to %s: %w", name, err)
+	}
+	fmt.Printf("✓ Switched to branch: %s\n", name)
+	return nil
+}
+
+// resolveBase returns the branch new branches are created from: the freshly
+// fetched default branch of the remote with FetchBase, falling back to the
+// local default branch
+func resolveBase(ctx context.Context, cfg config.Config) (string, error) {
+	if cfg.FetchBase {
+		fmt.Printf("Fetching %s...\n", cfg.Remote)
+		base, err := git.FetchBase(ctx, cfg.Remote)
+		if err == nil {
+			return base, nil
+		}
+		fmt.Printf("⚠ Could not fetch %s, branching from the local default branch: %v\n", cfg.Remote, err)
+	}
+	return git.GetDefaultBranch(ctx)
+}
+
+// setUpstream makes a new branch track its future counterpart on the
+// remote, so that the first push needs no extra flags
+func setUpstream(ctx context.Context, cfg config.Config, name string) {
+	if !git.RemoteExists(ctx, cfg.Remote) {
+		return
+	}
+	if err := git.SetUpstream(ctx, name, cfg.Remote); err != nil {
+		fmt.Printf("⚠ Could not set the upstream of %s: %v\n", name, err)
+	}
+}
+
+// withStash stashes changes to tracked files, runs op and re-applies the
+// changes afterwards, on the new branch if op succeeded. The stash is
+// labelled with the issue identifier so it can be found if re-applying fails.
+func withStash(ctx context.Context, identifier string, op func() error) error {
+	current, err := git.GetCurrentBranch(ctx)
+	if err != nil {
+		return err
+	}
+	label := git.StashMessage(identifier, current)
+	commit, err := git.StashPush(ctx, label)
+	if err != nil {
+		return fmt.Errorf("failed to stash changes: %w", err)
+	}
+
+	opErr := op()
+	if commit == "" {
+		return opErr
+	}
+	if err := git.StashPop(ctx, commit); err != nil {
+		fmt.Printf("⚠ Could not re-apply your changes, they are kept in the stash %q\n", label)
+	}
+	return opErr
+}
+
+// printWorktree reports the worktree a branch is checked out in
+func printWorktree(name, path string) {
+	fmt.Printf("✓ Branch %s is checked out in worktree: %s\n", name, path)
+	fmt.Printf("  cd %s\n", path)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// confirm asks a yes/no question on stdin, returning def on empty input
+func confirm(question string, def bool) bool {
+	choices := "[y/N]"
+	if def {
+		choices = "[Y/n]"
+	}
+	fmt.Printf("%s %s ", question, choices)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return def
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	case "n", "no":
+		return false
+	}
+	return def
+}
+
+// choose asks to pick one of the numbered options on stdin, returning the
+// index of the choice, def on empty input and -1 on an invalid answer
+func choose(question string, options []string, def int) int {
+	fmt.Println(question)
+	for i, option := range options {
+		fmt.Printf("  %d) %s\n", i+1, option)
+	}
+	fmt.Printf("Choice [%d]: ", def+1)
+
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return def
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return def
+	}
+
+	n, err := strconv.Atoi(answer)
+	if err != nil || n < 1 || n > len(options) {
+		return -1
+	}
+	return n - 1
+}
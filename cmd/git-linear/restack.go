@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/metalgrid/git-linear/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var restackCmd = &cobra.Command{
+	Use:   "restack [BRANCH]",
+	Short: "Rebase stacked issue branches onto their parents",
+	Long: `Rebase the stack of issue branches containing BRANCH (default: the current
+branch) so that every branch is on top of its parent again, starting from the
+bottom of the stack. Run it after changing a branch that others are stacked on.
+
+Branches are stacked by creating them from another issue branch, either with
+--base or by changing the base on the confirm screen.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRestack,
+}
+
+func init() {
+	rootCmd.AddCommand(restackCmd)
+}
+
+func runRestack(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	if !git.IsInsideWorkTree(ctx) {
+		return fmt.Errorf("not a git repository. Run this from inside a git project")
+	}
+
+	state, err := checkRepoState(ctx)
+	if err != nil {
+		return err
+	}
+	if state.Detached {
+		return fmt.Errorf("HEAD is detached. Switch to a branch before restacking")
+	}
+
+	if git.HasUncommittedChanges(ctx) {
+		return fmt.Errorf("you have uncommitted changes. Please commit or stash them before restacking")
+	}
+
+	current, err := git.GetCurrentBranch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current branch: %w", err)
+	}
+	name := current
+	if len(args) == 1 {
+		name = args[0]
+	}
+
+	parents, err := git.StackParents(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to read stacked branches: %w", err)
+	}
+	root := git.StackRoot(parents, name)
+	stack := git.StackOrder(parents, root)
+	if len(stack) == 0 {
+		fmt.Printf("No branches are stacked on %s.\n", root)
+		return nil
+	}
+
+	// A rebase killed half-way leaves the repository mid-rebase, so Ctrl+C
+	// only stops before the next branch
+	gitCtx := context.WithoutCancel(ctx)
+	rebased := 0
+	for _, branch := range stack {
+		if ctx.Err() != nil {
+			break
+		}
+		parent := parents[branch]
+		if !git.LocalBranchExists(ctx, branch) {
+			continue
+		}
+		if !git.LocalBranchExists(ctx, parent) {
+			return fmt.Errorf("%s is stacked on %s, which no longer exists", branch, parent)
+		}
+
+		fmt.Printf("Rebasing %s onto %s...\n", branch, parent)
+		if err := git.Rebase(gitCtx, branch, parent); err != nil {
+			return fmt.Errorf("failed to rebase %s onto %s: %w. Resolve the conflicts, run 'git rebase --continue' and then 'git linear restack' again", branch, parent, err)
+		}
+		rebased++
+	}
+
+	if err := git.SwitchBranch(gitCtx, current); err != nil {
+		return fmt.Errorf("failed to switch back to %s: %w", current, err)
+	}
+	if ctx.Err() != nil {
+		return fmt.Errorf("interrupted after restacking %d branches on %s", rebased, root)
+	}
+	fmt.Printf("✓ Restacked %d branches on %s\n", rebased, root)
+	return nil
+}
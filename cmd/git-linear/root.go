@@ -1,11 +1,15 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/metalgrid/git-linear/internal/auth"
+	"github.com/metalgrid/git-linear/internal/branch"
+	"github.com/metalgrid/git-linear/internal/config"
 	"github.com/metalgrid/git-linear/internal/git"
 	"github.com/metalgrid/git-linear/internal/linear"
 	"github.com/metalgrid/git-linear/internal/tui"
@@ -13,35 +17,123 @@ import (
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "git-linear",
+	Use:   "git-linear [ISSUE-ID | ISSUE-URL]",
 	Short: "Create git branches from Linear issues",
-	Long:  `git-linear is a CLI tool that helps you create properly-named git branches from your assigned Linear issues.`,
-	RunE:  runRoot,
+	Long: `git-linear is a CLI tool that helps you create properly-named git branches from your assigned Linear issues.
+
+Without arguments it opens an interactive picker. Given an issue identifier
+or Linear issue URL it creates or switches to the issue's branch directly.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRoot,
+}
+
+var (
+	pageSize       int
+	maxIssues      int
+	branchStrategy string
+	startIssue     bool
+	branchSuffix   string
+	assumeYes      bool
+	worktree       bool
+	remote         string
+	fetchBase      bool
+	stackOn        string
+)
+
+func init() {
+	rootCmd.Flags().IntVar(&pageSize, "page-size", 50, "Number of issues fetched per request")
+	rootCmd.Flags().IntVar(&maxIssues, "max-issues", 0, "Maximum number of issues to load (0 for no limit)")
+	rootCmd.Flags().StringVar(&branchStrategy, "branch-strategy", "", "Branch naming strategy: sanitize or linear (default from git config linear.branchStrategy)")
+	rootCmd.Flags().BoolVar(&startIssue, "start", false, "Move the issue to started after checking out its branch (default from git config linear.startIssue)")
+	rootCmd.Flags().BoolVar(&worktree, "worktree", false, "Check the branch out in its own git worktree (default from git config linear.worktree)")
+	rootCmd.Flags().StringVar(&remote, "remote", "", "Remote new branches track and are fetched from (default from git config linear.remote, or origin)")
+	rootCmd.Flags().BoolVar(&fetchBase, "fetch", false, "Fetch the remote and create new branches from its default branch (default from git config linear.fetchBase)")
+	rootCmd.Flags().StringVar(&stackOn, "base", "", "Create the branch from this branch instead of the default branch and stack it on top (with an issue argument)")
+	rootCmd.Flags().StringVar(&branchSuffix, "suffix", "", "Custom branch description used instead of the issue title (with an issue argument)")
+	rootCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Do not ask for confirmation (with an issue argument)")
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
 	// Check if inside git repo
-	if !git.IsInsideWorkTree() {
+	if !git.IsInsideWorkTree(ctx) {
 		return fmt.Errorf("not a git repository. Run this from inside a git project")
 	}
 
-	// Check for uncommitted changes
-	if git.HasUncommittedChanges() {
-		return fmt.Errorf("you have uncommitted changes. Please commit or stash them before creating a new branch")
+	state, err := checkRepoState(ctx)
+	if err != nil {
+		return err
+	}
+	if warning := state.Warning(); warning != "" {
+		fmt.Fprintf(os.Stderr, "⚠ %s\n", warning)
 	}
 
-	// Get API key from keyring
-	apiKey, err := auth.GetAPIKey()
+	// Load per-repository settings, letting flags override them
+	cfg, err := config.Load(ctx)
 	if err != nil {
-		return fmt.Errorf("no API key found. Run 'git linear auth' to set up your Linear API key")
+		return err
+	}
+	if cmd.Flags().Changed("branch-strategy") {
+		cfg.BranchStrategy, err = branch.ParseStrategy(branchStrategy)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Create Linear client
-	client := linear.NewClient(apiKey)
+	if cmd.Flags().Changed("start") {
+		cfg.StartIssue = startIssue
+	}
+	if cmd.Flags().Changed("worktree") {
+		cfg.Worktree = worktree
+	}
+	if cmd.Flags().Changed("remote") {
+		cfg.Remote = remote
+	}
+	if cmd.Flags().Changed("fetch") {
+		cfg.FetchBase = fetchBase
+	}
+	if err := cfg.Check(); err != nil {
+		return err
+	}
+
+	client, err := newClient()
+	if err != nil {
+		return err
+	}
+
+	username, err := templateUsername(ctx, client, cfg)
+	if err != nil {
+		return err
+	}
+
+	// Skip the TUI when the issue is given on the command line
+	if len(args) == 1 {
+		return runIssue(ctx, client, cfg, username, args[0])
+	}
+
+	repo, err := git.Open(cfg.Backend)
+	if err != nil {
+		return err
+	}
 
 	// Create and run TUI
-	model := tui.NewModel(client)
-	p := tea.NewProgram(model)
+	model := tui.NewModel(ctx, client, tui.Options{
+		Issues: linear.IssueQueryOptions{
+			PageSize: pageSize,
+			Limit:    maxIssues,
+		},
+		BranchStrategy: cfg.BranchStrategy,
+		BranchTemplate: cfg.BranchTemplate,
+		Username:       username,
+		StartIssue:     cfg.StartIssue,
+		Worktree:       cfg.Worktree,
+		WorktreeLayout: cfg.WorktreeLayout,
+		Remote:         cfg.Remote,
+		FetchBase:      cfg.FetchBase,
+		Repository:     repo,
+	})
+	p := tea.NewProgram(model, tea.WithContext(ctx))
 	if _, err := p.Run(); err != nil {
 		return fmt.Errorf("error running TUI: %w", err)
 	}
@@ -49,8 +141,43 @@ func runRoot(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// newClient creates a Linear client using the API key from the keyring
+func newClient() (*linear.Client, error) {
+	apiKey, err := auth.GetAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("no API key found. Run 'git linear auth' to set up your Linear API key")
+	}
+	return linear.NewClient(apiKey), nil
+}
+
+// templateUsername fetches the Linear username if the branch template uses it
+func templateUsername(ctx context.Context, client *linear.Client, cfg config.Config) (string, error) {
+	if !cfg.BranchTemplate.UsesUsername() {
+		return "", nil
+	}
+	viewer, err := client.GetViewer(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch your Linear username: %w", err)
+	}
+	return viewer.DisplayName, nil
+}
+
+// checkRepoState refuses to touch branches while a git operation such as a
+// rebase is in progress or the current branch has no commits
+func checkRepoState(ctx context.Context) (git.RepoState, error) {
+	state, err := git.GetRepoState(ctx)
+	if err != nil {
+		return state, fmt.Errorf("failed to read repository state: %w", err)
+	}
+	return state, state.Check()
+}
+
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
 		os.Exit(1)
 	}
 }
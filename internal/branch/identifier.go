@@ -0,0 +1,51 @@
+package branch
+
+import (
+	"regexp"
+	"strings"
+)
+
+// identifierPattern matches a Linear issue identifier (team key, hyphen,
+// number) at the start of a string
+var identifierPattern = regexp.MustCompile(`^(?i)[a-z][a-z0-9]*-[0-9]+`)
+
+// ParseIdentifier extracts the Linear issue identifier from a branch name,
+// e.g. "feature/dev-123-fix-login" → "DEV-123". The identifier is returned
+// in Linear's uppercase form. If the name contains several candidates, the
+// first one returned by ParseIdentifiers is picked.
+func ParseIdentifier(name string) (string, bool) {
+	ids := ParseIdentifiers(name)
+	if len(ids) == 0 {
+		return "", false
+	}
+	return ids[0], true
+}
+
+// ParseIdentifiers returns everything in a branch name that looks like a
+// Linear issue identifier, delimited by the start or end of the name or a
+// separator. Templates may put fields such as the username before the
+// identifier, e.g. "jane-2/dev-1-fix", but the description comes last, so
+// candidates in later path components come first: DEV-1, then JANE-2.
+func ParseIdentifiers(name string) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	components := strings.Split(name, "/")
+	for i := len(components) - 1; i >= 0; i-- {
+		c := components[i]
+		for start := 0; start < len(c); start++ {
+			if start > 0 && !strings.ContainsRune(separators, rune(c[start-1])) {
+				continue
+			}
+			match := identifierPattern.FindString(c[start:])
+			end := start + len(match)
+			if match == "" || (end < len(c) && !strings.ContainsRune(separators, rune(c[end]))) {
+				continue
+			}
+			if id := strings.ToUpper(match); !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
@@ -0,0 +1,34 @@
+package branch
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTable("ParseIdentifier",
+	func(name, expected string) {
+		identifier, ok := ParseIdentifier(name)
+		Expect(ok).To(Equal(expected != ""))
+		Expect(identifier).To(Equal(expected))
+	},
+	Entry("sanitized name", "dev-123-fix-login", "DEV-123"),
+	Entry("bare identifier", "dev-123", "DEV-123"),
+	Entry("prefixed name", "feature/dev-123-fix-login", "DEV-123"),
+	Entry("user prefix", "jane/eng2-7-title", "ENG2-7"),
+	Entry("uppercase identifier", "DEV-42_Fix", "DEV-42"),
+	Entry("no identifier", "main", ""),
+	Entry("number without team key", "2024-10-release", ""),
+	Entry("username before the identifier", "jane-2/dev-1-fix", "DEV-1"),
+	Entry("type and team before the identifier", "fix/eng-2/dev-1-fix", "DEV-1"),
+)
+
+var _ = DescribeTable("ParseIdentifiers",
+	func(name string, expected []string) {
+		Expect(ParseIdentifiers(name)).To(Equal(expected))
+	},
+	Entry("single identifier", "dev-1-fix", []string{"DEV-1"}),
+	Entry("later components first", "jane-2/dev-1-fix", []string{"DEV-1", "JANE-2"}),
+	Entry("adjacent candidates", "dev-1-v-2", []string{"DEV-1", "V-2"}),
+	Entry("duplicates", "dev-1/dev-1-fix", []string{"DEV-1"}),
+	Entry("no identifier", "main", nil),
+)
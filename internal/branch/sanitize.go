@@ -3,7 +3,6 @@ package branch
 import (
 	"regexp"
 	"strings"
-	"unicode"
 )
 
 // Sanitize creates a valid git branch name from a Linear identifier and title.
@@ -13,61 +12,59 @@ import (
 // - Remove all chars except [a-z0-9-]
 // - Collapse multiple hyphens to single
 // - Strip leading/trailing hyphens
-// - Max total length: 32 chars (truncate title part if needed)
+// - Max total length: 32 chars (shorten title part between words if needed)
 // - If title becomes empty after sanitization, return just identifier
 func Sanitize(identifier, title string) string {
-	// Lowercase identifier
-	identifier = strings.ToLower(identifier)
+	return join(strings.ToLower(identifier), "-", Slugify(title), DefaultMaxLength, false)
+}
 
-	// Slugify title: replace spaces with hyphens
-	title = strings.ReplaceAll(title, " ", "-")
+// Slugify turns free text into a branch name fragment without adding a
+// prefix or truncating it:
+// - Transliterate non-ASCII letters, see Transliterate
+// - Replace spaces with hyphens
+// - Remove all chars except [a-zA-Z0-9-_./]
+// - Replace consecutive dots, collapse multiple hyphens
+// - Drop empty path components, leading dots and ".lock" suffixes
+// - Strip leading/trailing hyphens and dots
+func Slugify(s string) string {
+	// Spell letters in ASCII, dropping emoji and other symbols
+	s = Transliterate(s)
 
-	// Remove non-ASCII characters (emoji, unicode)
-	title = removeNonASCII(title)
+	// Replace spaces with hyphens
+	s = strings.ReplaceAll(s, " ", "-")
 
 	// Consecutive dots are invalid in Git refs
-	title = regexp.MustCompile(`\.\.+`).ReplaceAllString(title, "-")
+	s = regexp.MustCompile(`\.\.+`).ReplaceAllString(s, "-")
 
 	// Remove all chars except [a-zA-Z0-9-_./]
-	title = regexp.MustCompile(`[^a-zA-Z0-9-_./]`).ReplaceAllString(title, "")
+	s = regexp.MustCompile(`[^a-zA-Z0-9-_./]`).ReplaceAllString(s, "")
 
 	// Collapse multiple hyphens to single
-	title = regexp.MustCompile(`-+`).ReplaceAllString(title, "-")
-
-	// Strip leading/trailing hyphens
-	title = strings.Trim(title, "-")
-
-	// If title is empty, return just identifier
-	if title == "" {
-		return identifier
-	}
+	s = regexp.MustCompile(`-+`).ReplaceAllString(s, "-")
 
-	// Combine identifier and title
-	result := identifier + "-" + title
-
-	// Truncate to max 32 chars if needed
-	if len(result) > 32 {
-		// Calculate how much space we have for the title
-		maxTitleLen := 32 - len(identifier) - 1 // -1 for the hyphen between identifier and title
-		if maxTitleLen < 1 {
-			// If identifier itself is too long, just return it truncated
-			return identifier[:32]
+	// Strip leading/trailing hyphens and dots, and path components git
+	// refuses, until nothing changes
+	for {
+		cleaned := strings.Trim(cleanComponents(s), "-.")
+		if cleaned == s {
+			return s
 		}
-		// Truncate title and remove trailing hyphen if present
-		title = title[:maxTitleLen]
-		title = strings.TrimRight(title, "-")
-		result = identifier + "-" + title
+		s = cleaned
 	}
-
-	return result
 }
 
-// removeNonASCII removes all non-ASCII characters from a string
-func removeNonASCII(s string) string {
-	return strings.Map(func(r rune) rune {
-		if r > unicode.MaxASCII {
-			return -1 // Remove the character
+// cleanComponents drops empty path components, and the dots git does not
+// allow at the start of a component or in a trailing ".lock"
+func cleanComponents(s string) string {
+	var components []string
+	for _, c := range strings.Split(s, "/") {
+		c = strings.TrimLeft(c, ".")
+		for strings.HasSuffix(c, ".lock") {
+			c = strings.TrimSuffix(c, ".lock")
 		}
-		return r
-	}, s)
+		if c != "" {
+			components = append(components, c)
+		}
+	}
+	return strings.Join(components, "/")
 }
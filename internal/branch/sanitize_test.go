@@ -65,7 +65,70 @@ var _ = Describe("Sanitize", func() {
 		Expect(Sanitize("DEV-1", "v1..0")).To(Equal("dev-1-v1-0"))
 	})
 
+	It("truncates between words", func() {
+		Expect(Sanitize("DEV-123", "Implement the authentication flow")).To(Equal("dev-123-Implement-authentication"))
+		Expect(Sanitize("DEV-123", "Add retries to webhook delivery")).To(Equal("dev-123-Add-retries-to-webhook"))
+	})
+
+	It("produces names git accepts", func() {
+		for _, title := range []string{
+			"update yarn.lock", "config.lock.", "/etc//hosts/", ".env/.secrets", "v1.0.", "@{upstream}", "-.-",
+		} {
+			Expect(Validate(Sanitize("DEV-1", title))).To(Succeed(), title)
+		}
+		Expect(Sanitize("DEV-1", "update yarn.lock")).To(Equal("dev-1-update-yarn"))
+		Expect(Sanitize("DEV-1", "/etc//hosts/")).To(Equal("dev-1-etc/hosts"))
+		Expect(Sanitize("DEV-1", ".env/.secrets")).To(Equal("dev-1-env/secrets"))
+	})
+
 	It("still removes invalid Git characters", func() {
 		Expect(Sanitize("DEV-1", "Fix~Login@Bug")).To(Equal("dev-1-FixLoginBug"))
 	})
 })
+
+var _ = DescribeTable("Transliterate",
+	func(title, expected string) {
+		Expect(Slugify(title)).To(Equal(expected))
+	},
+	Entry("German umlauts", "Größe über Maß ändern", "Groesse-ueber-Mass-aendern"),
+	Entry("capitalized umlaut", "Übersicht", "Uebersicht"),
+	Entry("Polish", "Łódź żółć", "Lodz-zolc"),
+	Entry("French and Spanish diacritics", "Café niño façade", "Cafe-nino-facade"),
+	Entry("Bulgarian", "Щастлив Юнак", "Shtastliv-Yunak"),
+	Entry("Russian", "Ошибка входа", "Oshibka-vhoda"),
+	Entry("Greek", "Σφάλμα σύνδεσης", "Sfalma-syndesis"),
+	Entry("Japanese is dropped between words", "Fix ログイン bug", "Fix-bug"),
+	Entry("unsupported script keeps words apart", "fix認証bug", "fix-bug"),
+	Entry("typographic punctuation", "Don’t — “quote”", "Dont-quote"),
+	Entry("compatibility forms", "ﬁle ½", "file-12"),
+)
+
+var _ = Describe("Sanitize with non-ASCII titles", func() {
+	It("keeps the transliterated title", func() {
+		Expect(Sanitize("DEV-1", "Ошибка")).To(Equal("dev-1-Oshibka"))
+	})
+
+	It("returns just the identifier for untransliterable titles", func() {
+		Expect(Sanitize("DEV-1", "ログイン")).To(Equal("dev-1"))
+	})
+})
+
+var _ = DescribeTable("Shorten",
+	func(slug string, maxLength int, dropStopWords bool, expected string) {
+		Expect(Shorten(slug, maxLength, dropStopWords)).To(Equal(expected))
+	},
+	Entry("fits", "fix-login", 9, false, "fix-login"),
+	Entry("fits with stop words", "fix-the-login", 20, true, "fix-the-login"),
+	Entry("no limit", "fix-the-login", 0, false, "fix-the-login"),
+	Entry("breaks between words", "fix-login-page-on-mobile", 14, false, "fix-login-page"),
+	Entry("skips stop words taking the room of the next word", "implement-the-authentication-flow", 24, false, "implement-authentication"),
+	Entry("keeps stop words that fit", "implement-the-authentication-flow", 28, false, "implement-the-authentication"),
+	Entry("keeps whole words", "add-retries-to-webhook-delivery", 22, false, "add-retries-to-webhook"),
+	Entry("does not end on a stop word", "fix-login-for-mobile-users", 14, false, "fix-login"),
+	Entry("drops stop words first", "implement-the-authentication-flow", 24, true, "implement-authentication"),
+	Entry("drops filler", "please-just-fix-the-login-page", 20, true, "fix-login-page"),
+	Entry("drops stop words ignoring case", "Fix-The-Login-On-Mobile", 16, true, "Fix-Login-Mobile"),
+	Entry("keeps other separators", "fix_login.page/v2-now", 16, false, "fix_login.page"),
+	Entry("only stop words", "of-the-and-for", 8, true, "of-the"),
+	Entry("cuts a single long word", strings.Repeat("a", 40), 10, false, strings.Repeat("a", 10)),
+)
@@ -0,0 +1,77 @@
+package branch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/metalgrid/git-linear/internal/linear"
+)
+
+// Strategy selects how branch names are generated for an issue
+type Strategy string
+
+const (
+	// StrategySanitize builds the name from the issue with a Template
+	StrategySanitize Strategy = "sanitize"
+	// StrategyLinear uses the branch name suggested by Linear verbatim
+	StrategyLinear Strategy = "linear"
+)
+
+// ParseStrategy parses a strategy name, defaulting to StrategySanitize when empty
+func ParseStrategy(s string) (Strategy, error) {
+	switch Strategy(strings.ToLower(strings.TrimSpace(s))) {
+	case "", StrategySanitize:
+		return StrategySanitize, nil
+	case StrategyLinear:
+		return StrategyLinear, nil
+	}
+	return "", fmt.Errorf("unknown branch naming strategy %q (expected %q or %q)", s, StrategySanitize, StrategyLinear)
+}
+
+// SplitLinearName splits a branch name suggested by Linear into the part up
+// to and including the issue identifier and the remaining description, so
+// that the description can be edited while the rest stays locked.
+// If the identifier does not appear in the name, the whole name is the prefix.
+func SplitLinearName(name, identifier string) (prefix, suffix string) {
+	idx := strings.Index(strings.ToLower(name), strings.ToLower(identifier))
+	if identifier == "" || idx < 0 {
+		return name, ""
+	}
+	end := idx + len(identifier)
+	return name[:end], strings.TrimPrefix(name[end:], "-")
+}
+
+// Suggestion is a suggested branch name split into a locked prefix and an
+// editable suffix
+type Suggestion struct {
+	Strategy Strategy
+	Prefix   string
+	// Separator joins the prefix and the suffix, e.g. "-" or "/"
+	Separator string
+	Suffix    string
+	// MaxLength is the length names are truncated to, 0 means no limit
+	MaxLength int
+	// DropStopWords drops all stop words from a suffix that is too long
+	DropStopWords bool
+}
+
+// Suggest returns the branch name suggested for an issue. linearName is the
+// name suggested by Linear; it is used with StrategyLinear when not empty,
+// otherwise the suggestion falls back to StrategySanitize, building the name
+// from t, or DefaultTemplate if t is nil.
+func Suggest(strategy Strategy, t *Template, issue linear.Issue, username, linearName string) Suggestion {
+	if strategy == StrategyLinear && linearName != "" {
+		prefix, suffix := SplitLinearName(linearName, issue.Identifier)
+		return Suggestion{Strategy: StrategyLinear, Prefix: prefix, Separator: "-", Suffix: suffix}
+	}
+	if t == nil {
+		t = defaultTemplate
+	}
+	return t.Suggest(issue, username)
+}
+
+// Name returns the full branch name. The suffix is slugified and shortened
+// to fit MaxLength; the prefix is kept verbatim.
+func (s Suggestion) Name() string {
+	return join(s.Prefix, s.Separator, Slugify(s.Suffix), s.MaxLength, s.DropStopWords)
+}
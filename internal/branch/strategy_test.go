@@ -0,0 +1,36 @@
+package branch
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseStrategy", func() {
+	It("defaults to sanitize", func() {
+		Expect(ParseStrategy("")).To(Equal(StrategySanitize))
+	})
+
+	It("accepts known strategies case-insensitively", func() {
+		Expect(ParseStrategy("Linear")).To(Equal(StrategyLinear))
+		Expect(ParseStrategy("sanitize")).To(Equal(StrategySanitize))
+	})
+
+	It("rejects unknown strategies", func() {
+		_, err := ParseStrategy("random")
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("SplitLinearName", func() {
+	It("splits after the identifier", func() {
+		prefix, suffix := SplitLinearName("jane/dev-123-fix-login", "DEV-123")
+		Expect(prefix).To(Equal("jane/dev-123"))
+		Expect(suffix).To(Equal("fix-login"))
+	})
+
+	It("returns the whole name as prefix when the identifier is missing", func() {
+		prefix, suffix := SplitLinearName("feature/login", "DEV-123")
+		Expect(prefix).To(Equal("feature/login"))
+		Expect(suffix).To(BeEmpty())
+	})
+})
@@ -0,0 +1,218 @@
+package branch
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/metalgrid/git-linear/internal/linear"
+)
+
+// DefaultTemplate builds the same names as Sanitize, e.g. dev-123-fix-login
+const DefaultTemplate = "{{.Identifier}}-{{.Slug}}"
+
+// DefaultMaxLength is the maximum length of names built from a template
+const DefaultMaxLength = 32
+
+// separators are the characters joining the parts of a branch name
+const separators = "-_./"
+
+// slugMarker stands in for the description while a template is executed
+const slugMarker = "\x00"
+
+// templateFuncs are the functions available to templates besides the
+// text/template builtins
+var templateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// repeatedSeparators matches the separators left around empty fields
+var repeatedSeparators = regexp.MustCompile(`([-_./])[-_./]+`)
+
+// defaultTemplate is used when no template is configured
+var defaultTemplate = mustParseTemplate(DefaultTemplate, DefaultMaxLength)
+
+// sampleIssue is used to check templates when they are parsed
+var sampleIssue = linear.Issue{
+	Identifier: "DEV-1",
+	Title:      "Title",
+	Priority:   linear.PriorityHigh,
+	Team:       linear.Team{Key: "DEV"},
+	Labels:     []linear.Label{{Name: "Feature"}},
+}
+
+// templateData holds the fields templates are executed with. Every value is
+// a lowercase slug, so that it can be used in a branch name as is.
+type templateData struct {
+	// Identifier is the issue identifier, e.g. dev-123
+	Identifier string
+	// Team is the key of the issue's team, e.g. dev
+	Team string
+	// Type is picked from the issue's labels by a TypeMap, e.g. fix
+	Type string
+	// Labels are all labels of the issue
+	Labels []string
+	// Priority is the issue's priority, e.g. high, or empty
+	Priority string
+	// Username is the Linear username of the user running git-linear
+	Username string
+	// Slug marks where the editable description goes
+	Slug string
+}
+
+// newTemplateData collects the fields of an issue for a template
+func newTemplateData(issue linear.Issue, username string, types TypeMap) templateData {
+	data := templateData{
+		Identifier: slug(issue.Identifier),
+		Team:       slug(issue.Team.Key),
+		Type:       slug(types.Type(issue.Labels)),
+		Username:   slug(username),
+		Slug:       slugMarker,
+	}
+	for _, label := range issue.Labels {
+		data.Labels = append(data.Labels, slug(label.Name))
+	}
+	if issue.Priority != linear.PriorityNone {
+		data.Priority = slug(issue.Priority.String())
+	}
+	return data
+}
+
+// slug turns an issue field into a lowercase branch name fragment
+func slug(s string) string {
+	return strings.ToLower(Slugify(s))
+}
+
+// Template builds branch names from a text/template, e.g.
+// "{{.Type}}/{{.Identifier}}-{{.Slug}}". The template must end with
+// {{.Slug}}: everything before it becomes the locked prefix of the name and
+// the issue title the editable description.
+type Template struct {
+	text      string
+	tmpl      *template.Template
+	maxLength int
+	types     TypeMap
+	// dropStopWords drops all stop words from descriptions that are too long
+	dropStopWords bool
+}
+
+// ParseTemplate parses a branch name template. Names are truncated to
+// maxLength characters, 0 means no limit.
+func ParseTemplate(text string, maxLength int) (*Template, error) {
+	tmpl, err := template.New("branch").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid branch template: %w", err)
+	}
+	if maxLength < 0 {
+		return nil, fmt.Errorf("invalid branch name length %d", maxLength)
+	}
+
+	// Unknown fields and a misplaced {{.Slug}} only show up when executed
+	t := &Template{text: text, tmpl: tmpl, maxLength: maxLength, types: DefaultTypeMap}
+	if _, _, err := t.prefix(sampleIssue, "user"); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// mustParseTemplate is like ParseTemplate but panics on errors
+func mustParseTemplate(text string, maxLength int) *Template {
+	t, err := ParseTemplate(text, maxLength)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// String returns the template text
+func (t *Template) String() string {
+	return t.text
+}
+
+// WithTypes returns a copy of the template picking {{.Type}} with types
+// instead of DefaultTypeMap
+func (t *Template) WithTypes(types TypeMap) *Template {
+	c := *t
+	c.types = types
+	return &c
+}
+
+// WithStopWordsDropped returns a copy of the template that drops all stop
+// words from descriptions that are too long, even where they would fit, see
+// Shorten
+func (t *Template) WithStopWordsDropped(drop bool) *Template {
+	c := *t
+	c.dropStopWords = drop
+	return &c
+}
+
+// UsesUsername reports whether names depend on the username, which has to
+// be fetched from Linear
+func (t *Template) UsesUsername() bool {
+	a, _, errA := t.prefix(sampleIssue, "a")
+	b, _, errB := t.prefix(sampleIssue, "b")
+	return errA != nil || errB != nil || a != b
+}
+
+// prefix executes the template and returns the locked part of the name
+// before the description, and the separator between the two. Separators
+// left around empty fields are dropped.
+func (t *Template) prefix(issue linear.Issue, username string) (prefix, sep string, err error) {
+	var b strings.Builder
+	if err := t.tmpl.Execute(&b, newTemplateData(issue, username, t.types)); err != nil {
+		return "", "", fmt.Errorf("invalid branch template: %w", err)
+	}
+	before, after, found := strings.Cut(b.String(), slugMarker)
+	if !found || after != "" {
+		return "", "", fmt.Errorf("invalid branch template %q: it must end with {{.Slug}}", t.text)
+	}
+
+	before = repeatedSeparators.ReplaceAllString(before, "$1")
+	before = strings.TrimLeft(before, separators)
+	prefix = strings.TrimRight(before, separators)
+	return prefix, before[len(prefix):], nil
+}
+
+// Suggest returns the name suggested for an issue. If the template fails
+// for this issue, e.g. because it indexes labels the issue does not have,
+// DefaultTemplate is used instead.
+func (t *Template) Suggest(issue linear.Issue, username string) Suggestion {
+	prefix, sep, err := t.prefix(issue, username)
+	if err != nil {
+		prefix, sep = strings.ToLower(issue.Identifier), "-"
+	}
+	return Suggestion{
+		Strategy:  StrategySanitize,
+		Prefix:    prefix,
+		Separator: sep,
+		Suffix:    issue.Title,
+		MaxLength: t.maxLength,
+
+		DropStopWords: t.dropStopWords,
+	}
+}
+
+// join joins a prefix and a suffix, shortening the suffix with Shorten so
+// that the name is at most maxLength characters long. 0 means no limit.
+func join(prefix, sep, suffix string, maxLength int, dropStopWords bool) string {
+	if prefix == "" {
+		sep = ""
+	}
+	if maxLength > 0 && suffix != "" {
+		room := maxLength - len(prefix) - len(sep)
+		if room < 1 {
+			// The prefix alone is too long, just return it truncated
+			if len(prefix) > maxLength {
+				return prefix[:maxLength]
+			}
+			return prefix
+		}
+		suffix = Shorten(suffix, room, dropStopWords)
+	}
+	if suffix == "" {
+		return prefix
+	}
+	return prefix + sep + suffix
+}
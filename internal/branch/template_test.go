@@ -0,0 +1,79 @@
+package branch
+
+import (
+	"github.com/metalgrid/git-linear/internal/linear"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Template", func() {
+	issue := linear.Issue{
+		Identifier: "DEV-123",
+		Title:      "Fix Login Bug",
+		Priority:   linear.PriorityUrgent,
+		Team:       linear.Team{Key: "DEV"},
+		Labels:     []linear.Label{{Name: "Needs Review"}, {Name: "Bug"}},
+	}
+
+	DescribeTable("Suggest",
+		func(text string, issue linear.Issue, prefix, sep, name string) {
+			t, err := ParseTemplate(text, 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := t.Suggest(issue, "Jane")
+			Expect(s.Prefix).To(Equal(prefix))
+			Expect(s.Separator).To(Equal(sep))
+			Expect(s.Name()).To(Equal(name))
+		},
+		Entry("default template", DefaultTemplate, issue, "dev-123", "-", "dev-123-Fix-Login-Bug"),
+		Entry("type prefix", "{{.Type}}/{{.Identifier}}-{{.Slug}}", issue, "fix/dev-123", "-", "fix/dev-123-Fix-Login-Bug"),
+		Entry("username prefix", "{{.Username}}/{{.Identifier}}-{{.Slug}}", issue, "jane/dev-123", "-", "jane/dev-123-Fix-Login-Bug"),
+		Entry("uppercase identifier", "feature/{{upper .Identifier}}-{{.Slug}}", issue, "feature/DEV-123", "-", "feature/DEV-123-Fix-Login-Bug"),
+		Entry("team and priority", "{{.Team}}/{{.Priority}}/{{.Slug}}", issue, "dev/urgent", "/", "dev/urgent/Fix-Login-Bug"),
+		Entry("all labels", "{{range .Labels}}{{.}}-{{end}}{{.Slug}}", issue, "needs-review-bug", "-", "needs-review-bug-Fix-Login-Bug"),
+		Entry("empty fields drop their separators", "{{.Type}}/{{.Priority}}-{{.Identifier}}-{{.Slug}}",
+			linear.Issue{Identifier: "DEV-1", Title: "t"}, "dev-1", "-", "dev-1-t"),
+		Entry("description only", "{{.Slug}}", issue, "", "", "Fix-Login-Bug"),
+	)
+
+	It("rejects templates that do not end with the description", func() {
+		_, err := ParseTemplate("{{.Slug}}-{{.Identifier}}", 0)
+		Expect(err).To(MatchError(ContainSubstring("must end with {{.Slug}}")))
+
+		_, err = ParseTemplate("{{.Identifier}}", 0)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects unknown fields", func() {
+		_, err := ParseTemplate("{{.Assignee}}/{{.Slug}}", 0)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("shortens the description to the maximum length between words", func() {
+		t, err := ParseTemplate("feature/{{.Identifier}}-{{.Slug}}", 24)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Suggest(issue, "").Name()).To(Equal("feature/dev-123-Fix"))
+	})
+
+	It("falls back to the default template when the template fails", func() {
+		t, err := ParseTemplate("{{index .Labels 0}}/{{.Slug}}", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.Suggest(linear.Issue{Identifier: "DEV-1", Title: "t"}, "").Name()).To(Equal("dev-1-t"))
+	})
+
+	It("picks the type with the configured rules", func() {
+		t, err := ParseTemplate("{{.Type}}/{{.Identifier}}-{{.Slug}}", 0)
+		Expect(err).NotTo(HaveOccurred())
+		t = t.WithTypes(TypeMap{Rules: []TypeRule{{Label: "needs review", Type: "Review"}}, Fallback: "misc"})
+
+		Expect(t.Suggest(issue, "").Name()).To(Equal("review/dev-123-Fix-Login-Bug"))
+		Expect(t.Suggest(linear.Issue{Identifier: "DEV-1", Title: "t"}, "").Name()).To(Equal("misc/dev-1-t"))
+	})
+
+	It("reports whether names depend on the username", func() {
+		Expect(defaultTemplate.UsesUsername()).To(BeFalse())
+		t, err := ParseTemplate("{{.Username}}/{{.Slug}}", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(t.UsesUsername()).To(BeTrue())
+	})
+})
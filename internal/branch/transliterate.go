@@ -0,0 +1,80 @@
+package branch
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// transliterations spells lowercase letters that do not decompose into an
+// ASCII letter and a diacritic, or whose usual spelling differs from the
+// bare letter, in ASCII
+var transliterations = map[rune]string{
+	// German
+	'ä': "ae", 'ö': "oe", 'ü': "ue", 'ß': "ss",
+	// Other Latin letters
+	'æ': "ae", 'œ': "oe", 'ø': "o", 'ł': "l", 'đ': "d", 'ð': "d", 'þ': "th", 'ı': "i",
+	// Cyrillic, following the Bulgarian and Russian romanizations
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ж': "zh",
+	'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n",
+	'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u", 'ф': "f",
+	'х': "h", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "sht", 'ъ': "a", 'ь': "y",
+	'ю': "yu", 'я': "ya", 'ё': "yo", 'ы': "y", 'э': "e",
+	'і': "i", 'ї': "yi", 'є': "ye", 'ґ': "g", 'ў': "u", 'ј': "j", 'љ': "lj",
+	'њ': "nj", 'ћ': "c", 'ђ': "dj", 'џ': "dz", 'ѓ': "gj", 'ќ': "kj", 'ѕ': "dz",
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+}
+
+// Transliterate spells non-ASCII letters in ASCII, e.g. "Grüße" → "Gruesse",
+// "Привет" → "Privet", "Łódź" → "Lodz", keeping the case of the first letter.
+// Letters of scripts without a transliteration, such as Japanese, are
+// replaced with spaces to keep the surrounding words apart. Other non-ASCII
+// symbols, e.g. emoji and typographic quotes, are removed; dashes become
+// hyphens.
+func Transliterate(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFC.String(s) {
+		switch {
+		case r <= unicode.MaxASCII:
+			b.WriteRune(r)
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			if t, ok := transliterate(r); ok {
+				b.WriteString(t)
+				continue
+			}
+			// Strip diacritics and compatibility forms, e.g. é → e, ﬁ → fi
+			var t string
+			for _, d := range norm.NFKD.String(string(r)) {
+				if d <= unicode.MaxASCII {
+					t += string(d)
+				} else if dt, ok := transliterate(d); ok {
+					t += dt
+				}
+			}
+			if t == "" {
+				t = " "
+			}
+			b.WriteString(t)
+		case unicode.Is(unicode.Pd, r):
+			b.WriteByte('-')
+		case unicode.IsSpace(r):
+			b.WriteByte(' ')
+		}
+	}
+	return b.String()
+}
+
+// transliterate looks a letter up in transliterations, ignoring case
+func transliterate(r rune) (string, bool) {
+	lower := unicode.ToLower(r)
+	t, ok := transliterations[lower]
+	if ok && lower != r {
+		t = strings.ToUpper(t[:1]) + t[1:]
+	}
+	return t, ok
+}
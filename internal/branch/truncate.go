@@ -0,0 +1,131 @@
+package branch
+
+import (
+	"strings"
+)
+
+// stopWords are dropped from long slugs by Shorten. Besides articles and
+// prepositions they include filler that rarely helps to recognize a branch.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "the": true,
+	"of": true, "to": true, "in": true, "on": true, "at": true, "by": true,
+	"for": true, "from": true, "with": true, "into": true, "as": true,
+	"and": true, "or": true, "but": true, "so": true,
+	"is": true, "are": true, "be": true, "it": true, "its": true,
+	"this": true, "that": true, "these": true, "those": true,
+	"some": true, "any": true, "all": true,
+	"please": true, "just": true, "really": true, "very": true,
+	"basically": true, "actually": true, "maybe": true,
+}
+
+// word is a word of a slug and the separator before it
+type word struct {
+	sep  string
+	text string
+}
+
+// splitWords splits a slug into words at separators
+func splitWords(slug string) []word {
+	var words []word
+	sep := ""
+	for len(slug) > 0 {
+		i := strings.IndexAny(slug, separators)
+		if i < 0 {
+			i = len(slug)
+		}
+		if i > 0 {
+			words = append(words, word{sep: sep, text: slug[:i]})
+			sep = ""
+		}
+		if i < len(slug) {
+			sep += slug[i : i+1]
+			i++
+		}
+		slug = slug[i:]
+	}
+	return words
+}
+
+// joinWords joins words with their separators, dropping the one before the
+// first word
+func joinWords(words []word) string {
+	var b strings.Builder
+	for i, w := range words {
+		if i > 0 {
+			b.WriteString(w.sep)
+		}
+		b.WriteString(w.text)
+	}
+	return b.String()
+}
+
+// isStopWord reports whether a word is one of stopWords, ignoring case
+func isStopWord(w word) bool {
+	return stopWords[strings.ToLower(w.text)]
+}
+
+// Shorten shortens a slug to at most maxLength characters, breaking it
+// between words. It keeps as many leading words as fit, leaving out stop
+// words such as "the" or "to" where they would take the room of the next
+// word, and never ends on one. If dropStopWords is set, stop words are left
+// out altogether. A slug that already fits is returned unchanged, and one
+// whose first word alone is too long is cut off in the middle of it.
+func Shorten(slug string, maxLength int, dropStopWords bool) string {
+	if maxLength <= 0 || len(slug) <= maxLength {
+		return slug
+	}
+
+	words := splitWords(slug)
+	if len(words) == 0 {
+		return ""
+	}
+	var informative []int
+	for i, w := range words {
+		if !isStopWord(w) {
+			informative = append(informative, i)
+		}
+	}
+	// A title made of stop words only is kept as it is
+	if len(informative) == 0 {
+		for i := range words {
+			informative = append(informative, i)
+		}
+	}
+
+	// Keep as many leading informative words as fit on their own
+	keep := make([]bool, len(words))
+	fits := func() bool { return len(joinKept(words, keep)) <= maxLength }
+	n := 0
+	for ; n < len(informative); n++ {
+		keep[informative[n]] = true
+		if !fits() {
+			keep[informative[n]] = false
+			break
+		}
+	}
+	if n == 0 {
+		return words[informative[0]].text[:maxLength]
+	}
+
+	// Put back the stop words between them while there is room
+	if !dropStopWords {
+		for i := informative[0]; i < informative[n-1]; i++ {
+			if !keep[i] {
+				keep[i] = true
+				keep[i] = fits()
+			}
+		}
+	}
+	return joinKept(words, keep)
+}
+
+// joinKept joins the words marked to keep, see joinWords
+func joinKept(words []word, keep []bool) string {
+	var kept []word
+	for i, w := range words {
+		if keep[i] {
+			kept = append(kept, w)
+		}
+	}
+	return joinWords(kept)
+}
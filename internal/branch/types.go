@@ -0,0 +1,55 @@
+package branch
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/metalgrid/git-linear/internal/linear"
+)
+
+// TypeRule maps a Linear label to a branch type, e.g. Bug to fix
+type TypeRule struct {
+	Label string
+	Type  string
+}
+
+// TypeMap picks the type of an issue, available to templates as {{.Type}},
+// from its labels. Rules are in priority order: the first rule matching any
+// label of the issue wins.
+type TypeMap struct {
+	Rules []TypeRule
+	// Fallback is the type of issues no rule matches, may be empty
+	Fallback string
+}
+
+// DefaultTypeMap is used when no rules are configured
+var DefaultTypeMap = TypeMap{
+	Rules: []TypeRule{
+		{Label: "Bug", Type: "fix"},
+		{Label: "Feature", Type: "feat"},
+		{Label: "Improvement", Type: "chore"},
+	},
+}
+
+// ParseTypeRule parses a rule written as label=type, e.g. "Bug=fix"
+func ParseTypeRule(s string) (TypeRule, error) {
+	label, typ, ok := strings.Cut(s, "=")
+	label, typ = strings.TrimSpace(label), strings.TrimSpace(typ)
+	if !ok || label == "" || typ == "" {
+		return TypeRule{}, fmt.Errorf("invalid branch type %q (expected label=type, e.g. Bug=fix)", s)
+	}
+	return TypeRule{Label: label, Type: typ}, nil
+}
+
+// Type returns the type of an issue with the given labels. Labels are
+// matched ignoring case.
+func (m TypeMap) Type(labels []linear.Label) string {
+	for _, rule := range m.Rules {
+		for _, label := range labels {
+			if strings.EqualFold(label.Name, rule.Label) {
+				return rule.Type
+			}
+		}
+	}
+	return m.Fallback
+}
@@ -0,0 +1,45 @@
+package branch
+
+import (
+	"github.com/metalgrid/git-linear/internal/linear"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTable("TypeMap.Type",
+	func(labels []string, expected string) {
+		types := TypeMap{
+			Rules: []TypeRule{
+				{Label: "Bug", Type: "fix"},
+				{Label: "Feature", Type: "feat"},
+			},
+			Fallback: "chore",
+		}
+		var issueLabels []linear.Label
+		for _, name := range labels {
+			issueLabels = append(issueLabels, linear.Label{Name: name})
+		}
+		Expect(types.Type(issueLabels)).To(Equal(expected))
+	},
+	Entry("matching label", []string{"Feature"}, "feat"),
+	Entry("label case is ignored", []string{"bug"}, "fix"),
+	Entry("earlier rule wins", []string{"Feature", "Bug"}, "fix"),
+	Entry("no matching label", []string{"Docs"}, "chore"),
+	Entry("no labels", nil, "chore"),
+)
+
+var _ = DescribeTable("ParseTypeRule",
+	func(s string, expected TypeRule) {
+		rule, err := ParseTypeRule(s)
+		if expected == (TypeRule{}) {
+			Expect(err).To(HaveOccurred())
+			return
+		}
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rule).To(Equal(expected))
+	},
+	Entry("label and type", "Bug=fix", TypeRule{Label: "Bug", Type: "fix"}),
+	Entry("spaces", " Tech Debt = chore ", TypeRule{Label: "Tech Debt", Type: "chore"}),
+	Entry("missing type", "Bug=", TypeRule{}),
+	Entry("missing separator", "Bug", TypeRule{}),
+)
@@ -0,0 +1,100 @@
+package branch
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InvalidNameError lists the rules a branch name breaks
+type InvalidNameError struct {
+	Name    string
+	Reasons []string
+}
+
+// Error implements error
+func (e *InvalidNameError) Error() string {
+	return fmt.Sprintf("invalid branch name %q: %s", e.Name, strings.Join(e.Reasons, "; "))
+}
+
+// Validate checks a branch name against the rules of git check-ref-format
+// for refs/heads/<name>, plus git branch's own refusal of names starting
+// with "-" and of HEAD. It returns an *InvalidNameError with a reason for
+// every rule the name breaks, or nil if the name is valid.
+func Validate(name string) error {
+	var reasons []string
+	add := func(format string, args ...any) {
+		reasons = append(reasons, fmt.Sprintf(format, args...))
+	}
+
+	if name == "" {
+		add("it is empty")
+		return &InvalidNameError{Name: name, Reasons: reasons}
+	}
+
+	if name == "@" {
+		add(`it cannot be "@"`)
+	}
+	if name == "HEAD" {
+		add(`it cannot be "HEAD"`)
+	}
+	if strings.HasPrefix(name, "-") {
+		add(`it cannot start with "-"`)
+	}
+	if strings.HasPrefix(name, "/") || strings.HasSuffix(name, "/") {
+		add(`it cannot start or end with "/"`)
+	}
+	if strings.Contains(name, "//") {
+		add(`it cannot contain "//"`)
+	}
+	if strings.HasSuffix(name, ".") {
+		add(`it cannot end with "."`)
+	}
+	if strings.Contains(name, "..") {
+		add(`it cannot contain ".."`)
+	}
+	if strings.Contains(name, "@{") {
+		add(`it cannot contain "@{"`)
+	}
+
+	for _, component := range strings.Split(name, "/") {
+		if strings.HasPrefix(component, ".") {
+			add("%q cannot start with \".\"", component)
+		}
+		if strings.HasSuffix(component, ".lock") {
+			add("%q cannot end with \".lock\"", component)
+		}
+	}
+
+	var invalid []string
+	for _, r := range name {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			invalid = append(invalid, "control characters")
+		case r == ' ':
+			invalid = append(invalid, "spaces")
+		case strings.ContainsRune(`~^:?*[\`, r):
+			invalid = append(invalid, fmt.Sprintf("%q", r))
+		}
+	}
+	if len(invalid) > 0 {
+		add("it cannot contain %s", strings.Join(unique(invalid), ", "))
+	}
+
+	if len(reasons) > 0 {
+		return &InvalidNameError{Name: name, Reasons: reasons}
+	}
+	return nil
+}
+
+// unique returns the values without duplicates, keeping their order
+func unique(values []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
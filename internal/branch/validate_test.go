@@ -0,0 +1,44 @@
+package branch
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTable("Validate",
+	func(name string, reasons ...string) {
+		err := Validate(name)
+		if len(reasons) == 0 {
+			Expect(err).NotTo(HaveOccurred())
+			return
+		}
+
+		var invalid *InvalidNameError
+		Expect(errors.As(err, &invalid)).To(BeTrue())
+		Expect(invalid.Reasons).To(HaveLen(len(reasons)))
+		for i, reason := range reasons {
+			Expect(invalid.Reasons[i]).To(ContainSubstring(reason))
+		}
+	},
+	Entry("simple name", "dev-123-fix-login"),
+	Entry("prefixed name", "feature/DEV-123-fix_login.v2"),
+	Entry("at sign without brace", "jane@home/dev-1"),
+	Entry("empty", "", "empty"),
+	Entry("single at sign", "@", `"@"`),
+	Entry("HEAD", "HEAD", `"HEAD"`),
+	Entry("leading hyphen", "-dev-1", `start with "-"`),
+	Entry("leading slash", "/dev-1", `start or end with "/"`),
+	Entry("trailing slash", "dev-1/", `start or end with "/"`),
+	Entry("double slash", "feature//dev-1", `"//"`),
+	Entry("trailing dot", "dev-1.", `end with "."`),
+	Entry("double dot", "dev..1", `".."`),
+	Entry("at brace", "dev@{1}", `"@{"`),
+	Entry("component with leading dot", "feature/.dev-1", `".dev-1" cannot start with "."`),
+	Entry("lock suffix", "dev-1.lock", `"dev-1.lock" cannot end with ".lock"`),
+	Entry("lock component", "dev.lock/x", `"dev.lock" cannot end with ".lock"`),
+	Entry("forbidden characters", "dev 1~2^3:4?5*6[7\\8", `spaces, '~', '^', ':', '?', '*', '[', '\\'`),
+	Entry("control character", "dev\t1", "control characters"),
+	Entry("several problems", "/dev..1.lock", `start or end with "/"`, `".."`, `cannot end with ".lock"`),
+)
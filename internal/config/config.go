@@ -0,0 +1,206 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/metalgrid/git-linear/internal/branch"
+	"github.com/metalgrid/git-linear/internal/git"
+)
+
+// Git config keys read by Load. Set them per repository with
+// `git config linear.<key> <value>` or globally with --global.
+const (
+	KeyBranchStrategy     = "linear.branchStrategy"
+	KeyStartIssue         = "linear.startIssue"
+	KeyFinishState        = "linear.finishState"
+	KeyWorktree           = "linear.worktree"
+	KeyWorktreeLayout     = "linear.worktreeLayout"
+	KeyRemote             = "linear.remote"
+	KeyFetchBase          = "linear.fetchBase"
+	KeyBackend            = "linear.backend"
+	KeyBranchTemplate     = "linear.branchTemplate"
+	KeyBranchMaxLength    = "linear.branchMaxLength"
+	KeyBranchType         = "linear.branchType"
+	KeyBranchTypeFallback = "linear.branchTypeFallback"
+	KeyBranchStopWords    = "linear.branchStopWords"
+)
+
+// Config holds the settings of git-linear for the current repository
+type Config struct {
+	// BranchStrategy selects how branch names are generated
+	BranchStrategy branch.Strategy
+	// StartIssue moves the issue to its team's first started state once
+	// its branch has been created or switched to
+	StartIssue bool
+	// FinishState is the name of the workflow state issues are moved to by
+	// the finish command; empty leaves the issue state unchanged
+	FinishState string
+	// Worktree checks issue branches out in their own git worktree instead
+	// of switching the current one
+	Worktree bool
+	// WorktreeLayout is the path template of new worktrees, see git.WorktreePath
+	WorktreeLayout string
+	// Remote is the remote new branches track and, with FetchBase, are
+	// created from
+	Remote string
+	// FetchBase fetches the default branch of Remote and creates new
+	// branches from it instead of from the local default branch
+	FetchBase bool
+	// Backend selects how the TUI operates on the repository
+	Backend git.Backend
+	// BranchTemplate builds branch names with the sanitize strategy
+	BranchTemplate *branch.Template
+}
+
+// Load reads the configuration from git config, applying defaults for
+// unset keys
+func Load(ctx context.Context) (Config, error) {
+	var cfg Config
+
+	value, err := git.GetConfig(ctx, KeyBranchStrategy)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read %s: %w", KeyBranchStrategy, err)
+	}
+	cfg.BranchStrategy, err = branch.ParseStrategy(value)
+	if err != nil {
+		return cfg, fmt.Errorf("invalid %s: %w", KeyBranchStrategy, err)
+	}
+
+	if cfg.StartIssue, err = getBool(ctx, KeyStartIssue, false); err != nil {
+		return cfg, err
+	}
+
+	if cfg.FinishState, err = git.GetConfig(ctx, KeyFinishState); err != nil {
+		return cfg, fmt.Errorf("failed to read %s: %w", KeyFinishState, err)
+	}
+
+	if cfg.Worktree, err = getBool(ctx, KeyWorktree, false); err != nil {
+		return cfg, err
+	}
+
+	if cfg.WorktreeLayout, err = git.GetConfig(ctx, KeyWorktreeLayout); err != nil {
+		return cfg, fmt.Errorf("failed to read %s: %w", KeyWorktreeLayout, err)
+	}
+	if cfg.WorktreeLayout == "" {
+		cfg.WorktreeLayout = git.DefaultWorktreeLayout
+	}
+
+	if cfg.Remote, err = git.GetConfig(ctx, KeyRemote); err != nil {
+		return cfg, fmt.Errorf("failed to read %s: %w", KeyRemote, err)
+	}
+	if cfg.Remote == "" {
+		cfg.Remote = git.DefaultRemote
+	}
+
+	if cfg.FetchBase, err = getBool(ctx, KeyFetchBase, false); err != nil {
+		return cfg, err
+	}
+
+	value, err = git.GetConfig(ctx, KeyBackend)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read %s: %w", KeyBackend, err)
+	}
+	if cfg.Backend, err = git.ParseBackend(value); err != nil {
+		return cfg, fmt.Errorf("invalid %s: %w", KeyBackend, err)
+	}
+
+	text, err := git.GetConfig(ctx, KeyBranchTemplate)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to read %s: %w", KeyBranchTemplate, err)
+	}
+	if text == "" {
+		text = branch.DefaultTemplate
+	}
+	maxLength, err := getInt(ctx, KeyBranchMaxLength, branch.DefaultMaxLength)
+	if err != nil {
+		return cfg, err
+	}
+	if cfg.BranchTemplate, err = branch.ParseTemplate(text, maxLength); err != nil {
+		return cfg, fmt.Errorf("invalid %s: %w", KeyBranchTemplate, err)
+	}
+
+	types, err := loadTypeMap(ctx)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.BranchTemplate = cfg.BranchTemplate.WithTypes(types)
+
+	dropStopWords, err := getBool(ctx, KeyBranchStopWords, false)
+	if err != nil {
+		return cfg, err
+	}
+	cfg.BranchTemplate = cfg.BranchTemplate.WithStopWordsDropped(dropStopWords)
+
+	return cfg, cfg.Check()
+}
+
+// Check rejects settings that cannot be combined. Load checks the settings
+// it reads; call it again after overriding them, e.g. with flags.
+func (c Config) Check() error {
+	if c.Worktree && c.Backend == git.BackendGoGit {
+		return fmt.Errorf("%s cannot be used with %s=%s, which cannot create worktrees", KeyWorktree, KeyBackend, git.BackendGoGit)
+	}
+	return nil
+}
+
+// loadTypeMap reads the label to branch type rules, replacing the default
+// rules if any are configured
+func loadTypeMap(ctx context.Context) (branch.TypeMap, error) {
+	types := branch.DefaultTypeMap
+
+	values, err := git.GetConfigAll(ctx, KeyBranchType)
+	if err != nil {
+		return types, fmt.Errorf("failed to read %s: %w", KeyBranchType, err)
+	}
+	if len(values) > 0 {
+		types.Rules = nil
+		for _, value := range values {
+			rule, err := branch.ParseTypeRule(value)
+			if err != nil {
+				return types, fmt.Errorf("invalid %s: %w", KeyBranchType, err)
+			}
+			types.Rules = append(types.Rules, rule)
+		}
+	}
+
+	if types.Fallback, err = git.GetConfig(ctx, KeyBranchTypeFallback); err != nil {
+		return types, fmt.Errorf("failed to read %s: %w", KeyBranchTypeFallback, err)
+	}
+	return types, nil
+}
+
+// getInt reads an integer git config key
+func getInt(ctx context.Context, key string, def int) (int, error) {
+	value, err := git.GetConfig(ctx, key)
+	if err != nil {
+		return def, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return def, fmt.Errorf("invalid %s: %q is not a number", key, value)
+	}
+	return n, nil
+}
+
+// getBool reads a boolean git config key, accepting the same spellings as git
+func getBool(ctx context.Context, key string, def bool) (bool, error) {
+	value, err := git.GetConfig(ctx, key)
+	if err != nil {
+		return def, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	switch strings.ToLower(value) {
+	case "":
+		return def, nil
+	case "true", "yes", "on", "1":
+		return true, nil
+	case "false", "no", "off", "0":
+		return false, nil
+	}
+	return def, fmt.Errorf("invalid %s: %q is not a boolean", key, value)
+}
@@ -0,0 +1,176 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalgrid/git-linear/internal/branch"
+	"github.com/metalgrid/git-linear/internal/git"
+	"github.com/metalgrid/git-linear/internal/linear"
+)
+
+func TestConfig(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Config Suite")
+}
+
+var _ = Describe("Load", func() {
+	var (
+		tempDir string
+		oldCwd  string
+		ctx     context.Context
+	)
+
+	gitConfig := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"config"}, args...)...)
+		cmd.Dir = tempDir
+		Expect(cmd.Run()).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		var err error
+		tempDir, err = os.MkdirTemp("", "config-test-*")
+		Expect(err).NotTo(HaveOccurred())
+
+		cmd := exec.Command("git", "init")
+		cmd.Dir = tempDir
+		Expect(cmd.Run()).To(Succeed())
+
+		oldCwd, err = os.Getwd()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(os.Chdir(tempDir)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.Chdir(oldCwd)
+		os.RemoveAll(tempDir)
+	})
+
+	It("returns defaults when nothing is configured", func() {
+		cfg, err := Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.BranchStrategy).To(Equal(branch.StrategySanitize))
+		Expect(cfg.StartIssue).To(BeFalse())
+		Expect(cfg.Remote).To(Equal("origin"))
+		Expect(cfg.FetchBase).To(BeFalse())
+		Expect(cfg.Backend).To(Equal(git.BackendExec))
+		Expect(cfg.BranchTemplate.String()).To(Equal(branch.DefaultTemplate))
+	})
+
+	It("reads the branch template and length", func() {
+		gitConfig(KeyBranchTemplate, "{{.Username}}/{{.Identifier}}-{{.Slug}}")
+		gitConfig(KeyBranchMaxLength, "29")
+
+		cfg, err := Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.BranchTemplate.UsesUsername()).To(BeTrue())
+		Expect(cfg.BranchTemplate.Suggest(linear.Issue{Identifier: "DEV-1", Title: "fix the login flow everywhere"}, "jane").Name()).
+			To(Equal("jane/dev-1-fix-the-login-flow"))
+
+		gitConfig(KeyBranchStopWords, "true")
+		cfg, err = Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.BranchTemplate.Suggest(linear.Issue{Identifier: "DEV-1", Title: "fix the login flow everywhere"}, "jane").Name()).
+			To(Equal("jane/dev-1-fix-login-flow"))
+
+		gitConfig(KeyBranchTemplate, "{{.Slug}}-{{.Identifier}}")
+		_, err = Load(ctx)
+		Expect(err).To(MatchError(ContainSubstring(KeyBranchTemplate)))
+
+		gitConfig(KeyBranchTemplate, "{{.Identifier}}-{{.Slug}}")
+		gitConfig(KeyBranchMaxLength, "long")
+		_, err = Load(ctx)
+		Expect(err).To(MatchError(ContainSubstring(KeyBranchMaxLength)))
+	})
+
+	It("reads the git backend", func() {
+		gitConfig(KeyBackend, "go-git")
+
+		cfg, err := Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Backend).To(Equal(git.BackendGoGit))
+
+		gitConfig(KeyBackend, "libgit2")
+		_, err = Load(ctx)
+		Expect(err).To(MatchError(ContainSubstring(KeyBackend)))
+	})
+
+	It("rejects worktrees with the go-git backend", func() {
+		gitConfig(KeyBackend, "go-git")
+		gitConfig(KeyWorktree, "true")
+
+		_, err := Load(ctx)
+		Expect(err).To(MatchError(ContainSubstring(KeyWorktree)))
+
+		cfg := Config{Backend: git.BackendExec, Worktree: true}
+		Expect(cfg.Check()).To(Succeed())
+	})
+
+	It("reads the remote to branch from", func() {
+		gitConfig(KeyRemote, "upstream")
+		gitConfig(KeyFetchBase, "true")
+
+		cfg, err := Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.Remote).To(Equal("upstream"))
+		Expect(cfg.FetchBase).To(BeTrue())
+	})
+
+	It("reads the label to branch type rules in order", func() {
+		bug := linear.Issue{Identifier: "DEV-1", Title: "t", Labels: []linear.Label{{Name: "Feature"}, {Name: "Bug"}}}
+		gitConfig(KeyBranchTemplate, "{{.Type}}/{{.Identifier}}-{{.Slug}}")
+
+		cfg, err := Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.BranchTemplate.Suggest(bug, "").Name()).To(Equal("fix/dev-1-t"))
+
+		gitConfig("--add", KeyBranchType, "Feature=feat")
+		gitConfig("--add", KeyBranchType, "Bug=fix")
+		gitConfig(KeyBranchTypeFallback, "chore")
+		cfg, err = Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.BranchTemplate.Suggest(bug, "").Name()).To(Equal("feat/dev-1-t"))
+		Expect(cfg.BranchTemplate.Suggest(linear.Issue{Identifier: "DEV-2", Title: "t"}, "").Name()).To(Equal("chore/dev-2-t"))
+
+		gitConfig("--add", KeyBranchType, "Bug")
+		_, err = Load(ctx)
+		Expect(err).To(MatchError(ContainSubstring(KeyBranchType)))
+	})
+
+	It("reads the branch strategy", func() {
+		gitConfig(KeyBranchStrategy, "linear")
+
+		cfg, err := Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.BranchStrategy).To(Equal(branch.StrategyLinear))
+	})
+
+	It("rejects unknown branch strategies", func() {
+		gitConfig(KeyBranchStrategy, "bogus")
+
+		_, err := Load(ctx)
+		Expect(err).To(MatchError(ContainSubstring(KeyBranchStrategy)))
+	})
+
+	It("reads boolean keys using git's spellings", func() {
+		gitConfig(KeyStartIssue, "yes")
+
+		cfg, err := Load(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cfg.StartIssue).To(BeTrue())
+	})
+
+	It("rejects invalid booleans", func() {
+		gitConfig(KeyStartIssue, "maybe")
+
+		_, err := Load(ctx)
+		Expect(err).To(MatchError(ContainSubstring(KeyStartIssue)))
+	})
+})
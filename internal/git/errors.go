@@ -0,0 +1,111 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Sentinel errors classifying common git failures. Use errors.Is to test
+// for them; the *Error returned by package functions carries git's message.
+var (
+	ErrNotRepository     = errors.New("not a git repository")
+	ErrRefExists         = errors.New("branch already exists")
+	ErrInvalidBranchName = errors.New("invalid branch name")
+	ErrUnknownRevision   = errors.New("unknown revision")
+	ErrWouldOverwrite    = errors.New("local changes would be overwritten")
+	ErrIndexLocked       = errors.New("index is locked by another git process")
+)
+
+// Error is a failed git command
+type Error struct {
+	// Kind is one of the sentinel errors, or nil if the failure is not
+	// recognized
+	Kind error
+	// Args are the arguments git was run with
+	Args []string
+	// Stderr is what git printed on standard error
+	Stderr string
+	// Err is the error of the process, e.g. an *exec.ExitError
+	Err error
+}
+
+// Error implements error, preferring git's own message to the exit status
+func (e *Error) Error() string {
+	command := "git"
+	if len(e.Args) > 0 {
+		command += " " + e.Args[0]
+	}
+	if msg := e.Message(); msg != "" {
+		return fmt.Sprintf("%s: %s", command, msg)
+	}
+	return fmt.Sprintf("%s: %v", command, e.Err)
+}
+
+// Unwrap returns the kind and the process error, so that errors.Is works
+// with the sentinels and errors.As with *exec.ExitError
+func (e *Error) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// Message returns git's message without the "fatal:" or "error:" prefixes
+func (e *Error) Message() string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(e.Stderr), "\n") {
+		for _, prefix := range []string{"fatal: ", "error: "} {
+			line = strings.TrimPrefix(line, prefix)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// classify recognizes common failures from git's message
+func classify(stderr string) error {
+	switch {
+	case strings.Contains(stderr, "not a git repository"):
+		return ErrNotRepository
+	case strings.Contains(stderr, "index.lock"):
+		return ErrIndexLocked
+	case strings.Contains(stderr, "would be overwritten by"):
+		return ErrWouldOverwrite
+	case strings.Contains(stderr, "a branch named") && strings.Contains(stderr, "already exists"):
+		return ErrRefExists
+	case strings.Contains(stderr, "is not a valid branch name"):
+		return ErrInvalidBranchName
+	case strings.Contains(stderr, "not a valid object name"),
+		strings.Contains(stderr, "invalid reference"):
+		return ErrUnknownRevision
+	}
+	return nil
+}
+
+// run runs git with the arguments and returns its output. A failure is
+// returned as an *Error carrying git's message.
+func run(ctx context.Context, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	var out, stderr bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		// The process was killed, git's message does not matter
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", ctxErr
+		}
+		return "", &Error{
+			Kind:   classify(stderr.String()),
+			Args:   args,
+			Stderr: stderr.String(),
+			Err:    err,
+		}
+	}
+	return out.String(), nil
+}
@@ -2,14 +2,16 @@ package git
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
 )
 
 // IsInsideWorkTree checks if the current directory is inside a git repository.
-func IsInsideWorkTree() bool {
-	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+func IsInsideWorkTree(ctx context.Context) bool {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--is-inside-work-tree")
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	cmd.Stderr = nil // Suppress stderr
@@ -21,8 +23,8 @@ func IsInsideWorkTree() bool {
 }
 
 // HasUncommittedChanges checks if the working tree has uncommitted changes.
-func HasUncommittedChanges() bool {
-	cmd := exec.Command("git", "status", "--porcelain")
+func HasUncommittedChanges(ctx context.Context) bool {
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain")
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	err := cmd.Run()
@@ -34,9 +36,9 @@ func HasUncommittedChanges() bool {
 
 // GetDefaultBranch detects the default branch (main or master).
 // It tries origin/HEAD first, then falls back to common names.
-func GetDefaultBranch() (string, error) {
+func GetDefaultBranch(ctx context.Context) (string, error) {
 	// Try to get the default branch from origin/HEAD
-	cmd := exec.Command("git", "symbolic-ref", "refs/remotes/origin/HEAD")
+	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", "refs/remotes/origin/HEAD")
 	var out bytes.Buffer
 	cmd.Stdout = &out
 	if err := cmd.Run(); err == nil {
@@ -50,7 +52,7 @@ func GetDefaultBranch() (string, error) {
 
 	// Fallback: try common branch names
 	for _, name := range []string{"main", "master"} {
-		cmd := exec.Command("git", "rev-parse", "--verify", name)
+		cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", name)
 		if err := cmd.Run(); err == nil {
 			return name, nil
 		}
@@ -59,55 +61,102 @@ func GetDefaultBranch() (string, error) {
 	return "", fmt.Errorf("could not determine default branch")
 }
 
-// BranchExists checks if a branch exists (case-insensitive).
-func BranchExists(name string) bool {
-	// Get all branches and compare case-insensitively
-	cmd := exec.Command("git", "branch", "-a")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	if err := cmd.Run(); err != nil {
-		return false
+// LocalBranchExists checks if a local branch with exactly this name exists.
+func LocalBranchExists(ctx context.Context, name string) bool {
+	cmd := exec.CommandContext(ctx, "git", "show-ref", "--verify", "--quiet", "refs/heads/"+name)
+	return cmd.Run() == nil
+}
+
+// ListLocalBranches returns the names of all local branches.
+func ListLocalBranches(ctx context.Context) ([]string, error) {
+	out, err := run(ctx, "for-each-ref", "--format=%(refname:short)", "refs/heads/")
+	if err != nil {
+		return nil, err
 	}
+	return strings.Fields(out), nil
+}
 
-	branches := strings.Split(strings.TrimSpace(out.String()), "\n")
-	lowerName := strings.ToLower(name)
-
-	for _, branch := range branches {
-		// Remove leading/trailing whitespace and * (current branch indicator)
-		branch = strings.TrimSpace(branch)
-		branch = strings.TrimPrefix(branch, "* ")
-		// Remove remote prefix if present (e.g., "remotes/origin/")
-		if strings.Contains(branch, "/") {
-			parts := strings.Split(branch, "/")
-			branch = parts[len(parts)-1]
-		}
-		if strings.ToLower(branch) == lowerName {
-			return true
+// CreateBranch creates a new branch from a base branch. The branch does not
+// track base, even if base is a remote-tracking branch.
+func CreateBranch(ctx context.Context, name, base string) error {
+	_, err := run(ctx, "branch", "--no-track", name, base)
+	return err
+}
+
+// SwitchBranch switches to an existing local branch. Unlike a plain git
+// checkout it never creates a branch from a remote-tracking branch of the
+// same name, see CreateTrackingBranch.
+func SwitchBranch(ctx context.Context, name string) error {
+	_, err := run(ctx, "checkout", "--no-guess", name, "--")
+	return err
+}
+
+// GetCurrentBranch returns the name of the current branch.
+func GetCurrentBranch(ctx context.Context) (string, error) {
+	out, err := run(ctx, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// GetConfig returns the value of a git config key.
+// It returns an empty string if the key is not set.
+func GetConfig(ctx context.Context, key string) (string, error) {
+	out, err := run(ctx, "config", "--get", key)
+	if err != nil {
+		// Exit code 1 means the key is not set
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return "", nil
 		}
+		return "", err
 	}
+	return strings.TrimSpace(out), nil
+}
 
-	return false
+// GetConfigAll returns all values of a multi-valued git config key, in the
+// order they are configured. It returns nil if the key is not set.
+func GetConfigAll(ctx context.Context, key string) ([]string, error) {
+	out, err := run(ctx, "config", "--get-all", key)
+	if err != nil {
+		// Exit code 1 means the key is not set
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return strings.Split(strings.TrimSpace(out), "\n"), nil
 }
 
-// CreateBranch creates a new branch from a base branch.
-func CreateBranch(name, base string) error {
-	cmd := exec.Command("git", "branch", name, base)
-	return cmd.Run()
+// Push pushes a branch to a remote and sets it as the upstream.
+func Push(ctx context.Context, remote, name string) error {
+	_, err := run(ctx, "push", "--set-upstream", remote, name)
+	return err
 }
 
-// SwitchBranch switches to an existing branch.
-func SwitchBranch(name string) error {
-	cmd := exec.Command("git", "checkout", name)
-	return cmd.Run()
+// IsMerged checks if all commits of a branch are reachable from base.
+func IsMerged(ctx context.Context, name, base string) (bool, error) {
+	if _, err := run(ctx, "merge-base", "--is-ancestor", name, base); err != nil {
+		// Exit code 1 means the branch is not an ancestor of base
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
 }
 
-// GetCurrentBranch returns the name of the current branch.
-func GetCurrentBranch() (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	var out bytes.Buffer
-	cmd.Stdout = &out
-	if err := cmd.Run(); err != nil {
-		return "", err
+// DeleteBranch deletes a local branch that has been merged into HEAD or its
+// upstream. With force it is deleted anyway, e.g. when IsMerged found it
+// merged into a remote-tracking branch.
+func DeleteBranch(ctx context.Context, name string, force bool) error {
+	args := []string{"branch", "--delete", name}
+	if force {
+		args = append(args, "--force")
 	}
-	return strings.TrimSpace(out.String()), nil
+	_, err := run(ctx, args...)
+	return err
 }
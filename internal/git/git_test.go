@@ -1,9 +1,12 @@
 package git
 
 import (
+	"context"
+	"errors"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -16,9 +19,13 @@ func TestGit(t *testing.T) {
 }
 
 var _ = Describe("Git Operations", func() {
-	var tempDir string
+	var (
+		tempDir string
+		ctx     context.Context
+	)
 
 	BeforeEach(func() {
+		ctx = context.Background()
 		var err error
 		tempDir, err = os.MkdirTemp("", "git-test-*")
 		Expect(err).NotTo(HaveOccurred())
@@ -46,7 +53,7 @@ var _ = Describe("Git Operations", func() {
 			err = os.Chdir(tempDir)
 			Expect(err).NotTo(HaveOccurred())
 
-			result := IsInsideWorkTree()
+			result := IsInsideWorkTree(ctx)
 			Expect(result).To(BeTrue())
 		})
 
@@ -58,7 +65,7 @@ var _ = Describe("Git Operations", func() {
 			err = os.Chdir(tempDir)
 			Expect(err).NotTo(HaveOccurred())
 
-			result := IsInsideWorkTree()
+			result := IsInsideWorkTree(ctx)
 			Expect(result).To(BeFalse())
 		})
 	})
@@ -89,7 +96,7 @@ var _ = Describe("Git Operations", func() {
 			err = os.Chdir(tempDir)
 			Expect(err).NotTo(HaveOccurred())
 
-			result := HasUncommittedChanges()
+			result := HasUncommittedChanges(ctx)
 			Expect(result).To(BeFalse())
 		})
 
@@ -123,7 +130,7 @@ var _ = Describe("Git Operations", func() {
 			err = os.Chdir(tempDir)
 			Expect(err).NotTo(HaveOccurred())
 
-			result := HasUncommittedChanges()
+			result := HasUncommittedChanges(ctx)
 			Expect(result).To(BeTrue())
 		})
 	})
@@ -153,14 +160,14 @@ var _ = Describe("Git Operations", func() {
 			err = os.Chdir(tempDir)
 			Expect(err).NotTo(HaveOccurred())
 
-			branch, err := GetDefaultBranch()
+			branch, err := GetDefaultBranch(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(branch).To(Or(Equal("main"), Equal("master")))
 		})
 	})
 
-	Describe("BranchExists", func() {
-		It("returns true for existing branch", func() {
+	Describe("SnapshotRefs", func() {
+		It("finds an existing branch", func() {
 			cmd := exec.Command("git", "init")
 			cmd.Dir = tempDir
 			Expect(cmd.Run()).NotTo(HaveOccurred())
@@ -177,7 +184,7 @@ var _ = Describe("Git Operations", func() {
 			cmd.Dir = tempDir
 			Expect(cmd.Run()).NotTo(HaveOccurred())
 
-			cmd = exec.Command("git", "branch", "feature-test")
+			cmd = exec.Command("git", "branch", "dev-1-feature-test")
 			cmd.Dir = tempDir
 			Expect(cmd.Run()).NotTo(HaveOccurred())
 
@@ -188,11 +195,12 @@ var _ = Describe("Git Operations", func() {
 			err = os.Chdir(tempDir)
 			Expect(err).NotTo(HaveOccurred())
 
-			result := BranchExists("feature-test")
-			Expect(result).To(BeTrue())
+			refs, err := SnapshotRefs(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refs.Has("DEV-1")).To(BeTrue())
 		})
 
-		It("returns false for non-existing branch", func() {
+		It("does not find a non-existing branch", func() {
 			cmd := exec.Command("git", "init")
 			cmd.Dir = tempDir
 			Expect(cmd.Run()).NotTo(HaveOccurred())
@@ -216,8 +224,9 @@ var _ = Describe("Git Operations", func() {
 			err = os.Chdir(tempDir)
 			Expect(err).NotTo(HaveOccurred())
 
-			result := BranchExists("nonexistent-branch")
-			Expect(result).To(BeFalse())
+			refs, err := SnapshotRefs(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refs.Has("DEV-404")).To(BeFalse())
 		})
 
 		It("is case-insensitive", func() {
@@ -237,7 +246,7 @@ var _ = Describe("Git Operations", func() {
 			cmd.Dir = tempDir
 			Expect(cmd.Run()).NotTo(HaveOccurred())
 
-			cmd = exec.Command("git", "branch", "Feature-Test")
+			cmd = exec.Command("git", "branch", "DEV-1-Feature-Test")
 			cmd.Dir = tempDir
 			Expect(cmd.Run()).NotTo(HaveOccurred())
 
@@ -249,8 +258,9 @@ var _ = Describe("Git Operations", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			// Should find it regardless of case
-			result := BranchExists("feature-test")
-			Expect(result).To(BeTrue())
+			refs, err := SnapshotRefs(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(refs.Has("dev-1")).To(BeTrue())
 		})
 	})
 
@@ -280,15 +290,14 @@ var _ = Describe("Git Operations", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			// Get current branch (should be main or master)
-			currentBranch, err := GetCurrentBranch()
+			currentBranch, err := GetCurrentBranch(ctx)
 			Expect(err).NotTo(HaveOccurred())
 
-			err = CreateBranch("new-feature", currentBranch)
+			err = CreateBranch(ctx, "new-feature", currentBranch)
 			Expect(err).NotTo(HaveOccurred())
 
 			// Verify branch exists
-			result := BranchExists("new-feature")
-			Expect(result).To(BeTrue())
+			Expect(LocalBranchExists(ctx, "new-feature")).To(BeTrue())
 		})
 	})
 
@@ -321,14 +330,92 @@ var _ = Describe("Git Operations", func() {
 			err = os.Chdir(tempDir)
 			Expect(err).NotTo(HaveOccurred())
 
-			err = SwitchBranch("feature-branch")
+			err = SwitchBranch(ctx, "feature-branch")
 			Expect(err).NotTo(HaveOccurred())
 
 			// Verify we're on the right branch
-			current, err := GetCurrentBranch()
+			current, err := GetCurrentBranch(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(current).To(Equal("feature-branch"))
 		})
+
+		It("reports local changes that would be overwritten", func() {
+			for _, args := range [][]string{
+				{"init"},
+				{"config", "user.email", "test@example.com"},
+				{"config", "user.name", "Test User"},
+			} {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = tempDir
+				Expect(cmd.Run()).NotTo(HaveOccurred())
+			}
+			Expect(os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("v1"), 0644)).To(Succeed())
+			for _, args := range [][]string{
+				{"add", "file.txt"},
+				{"commit", "-m", "initial"},
+				{"checkout", "-b", "feature-branch"},
+			} {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = tempDir
+				Expect(cmd.Run()).NotTo(HaveOccurred())
+			}
+			Expect(os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("v2"), 0644)).To(Succeed())
+			for _, args := range [][]string{
+				{"commit", "-am", "change"},
+				{"checkout", "-"},
+			} {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = tempDir
+				Expect(cmd.Run()).NotTo(HaveOccurred())
+			}
+			Expect(os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("local"), 0644)).To(Succeed())
+
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = SwitchBranch(ctx, "feature-branch")
+			Expect(err).To(MatchError(ErrWouldOverwrite))
+			Expect(err.Error()).To(HavePrefix("git checkout: "))
+			Expect(err.Error()).To(ContainSubstring("file.txt"))
+
+			var gitErr *Error
+			Expect(errors.As(err, &gitErr)).To(BeTrue())
+			Expect(gitErr.Message()).NotTo(HavePrefix("error: "))
+		})
+
+		It("reports running outside a repository", func() {
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(SwitchBranch(ctx, "feature-branch")).To(MatchError(ErrNotRepository))
+		})
+
+		It("fails when the context is canceled", func() {
+			cmd := exec.Command("git", "init")
+			cmd.Dir = tempDir
+			Expect(cmd.Run()).NotTo(HaveOccurred())
+
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			canceled, cancel := context.WithCancel(ctx)
+			cancel()
+
+			err = SwitchBranch(canceled, "feature-branch")
+			Expect(err).To(MatchError(context.Canceled))
+		})
 	})
 
 	Describe("GetCurrentBranch", func() {
@@ -356,7 +443,7 @@ var _ = Describe("Git Operations", func() {
 			err = os.Chdir(tempDir)
 			Expect(err).NotTo(HaveOccurred())
 
-			branch, err := GetCurrentBranch()
+			branch, err := GetCurrentBranch(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(branch).To(Or(Equal("main"), Equal("master")))
 		})
@@ -389,12 +476,618 @@ var _ = Describe("Git Operations", func() {
 			err = os.Chdir(tempDir)
 			Expect(err).NotTo(HaveOccurred())
 
-			err = SwitchBranch("test-branch")
+			err = SwitchBranch(ctx, "test-branch")
 			Expect(err).NotTo(HaveOccurred())
 
-			branch, err := GetCurrentBranch()
+			branch, err := GetCurrentBranch(ctx)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(branch).To(Equal("test-branch"))
 		})
 	})
+
+	Describe("IsMerged and DeleteBranch", func() {
+		It("detects merged branches and deletes them", func() {
+			for _, args := range [][]string{
+				{"init"},
+				{"config", "user.email", "test@example.com"},
+				{"config", "user.name", "Test User"},
+				{"commit", "--allow-empty", "-m", "initial"},
+				{"branch", "merged-branch"},
+				{"checkout", "-b", "unmerged-branch"},
+				{"commit", "--allow-empty", "-m", "work"},
+				{"checkout", "-"},
+			} {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = tempDir
+				Expect(cmd.Run()).NotTo(HaveOccurred())
+			}
+
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			base, err := GetCurrentBranch(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			merged, err := IsMerged(ctx, "merged-branch", base)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged).To(BeTrue())
+
+			merged, err = IsMerged(ctx, "unmerged-branch", base)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged).To(BeFalse())
+
+			Expect(DeleteBranch(ctx, "merged-branch", false)).To(Succeed())
+			Expect(LocalBranchExists(ctx, "merged-branch")).To(BeFalse())
+			Expect(DeleteBranch(ctx, "unmerged-branch", false)).NotTo(Succeed())
+			Expect(DeleteBranch(ctx, "unmerged-branch", true)).To(Succeed())
+			Expect(LocalBranchExists(ctx, "unmerged-branch")).To(BeFalse())
+		})
+	})
+
+	Describe("WorktreePath", func() {
+		It("expands the default layout next to the main worktree", func() {
+			path, err := WorktreePath("", "/src/myrepo", "dev-123-fix")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal("/src/myrepo-worktrees/dev-123-fix"))
+		})
+
+		It("keeps absolute layouts", func() {
+			path, err := WorktreePath("/tmp/wt/{{.Branch}}", "/src/myrepo", "feature/dev-1")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(path).To(Equal("/tmp/wt/feature/dev-1"))
+		})
+
+		It("rejects unknown fields", func() {
+			_, err := WorktreePath("{{.Unknown}}", "/src/myrepo", "dev-1")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("EnsureWorktree", func() {
+		var repoDir string
+
+		BeforeEach(func() {
+			repoDir = filepath.Join(tempDir, "repo")
+			Expect(os.Mkdir(repoDir, 0755)).To(Succeed())
+			for _, args := range [][]string{
+				{"init"},
+				{"config", "user.email", "test@example.com"},
+				{"config", "user.name", "Test User"},
+				{"commit", "--allow-empty", "-m", "initial"},
+				{"branch", "existing-branch"},
+			} {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = repoDir
+				Expect(cmd.Run()).NotTo(HaveOccurred())
+			}
+		})
+
+		It("creates, reuses and finds worktrees", func() {
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(repoDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			base, err := GetCurrentBranch(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			// New branch
+			path, created, err := EnsureWorktree(ctx, "", "dev-1-new", base)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeTrue())
+			Expect(path).To(Equal(filepath.Join(tempDir, "repo-worktrees", "dev-1-new")))
+			Expect(LocalBranchExists(ctx, "dev-1-new")).To(BeTrue())
+
+			// Existing branch
+			_, created, err = EnsureWorktree(ctx, "", "existing-branch", base)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeTrue())
+
+			// Already checked out
+			again, created, err := EnsureWorktree(ctx, "", "dev-1-new", base)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created).To(BeFalse())
+			Expect(again).To(Equal(path))
+
+			wt, found, err := FindWorktree(ctx, "dev-1-new")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(wt.Path).To(Equal(path))
+		})
+	})
+
+	Describe("parseStatus", func() {
+		It("counts staged, modified, untracked and conflicted files", func() {
+			status := parseStatus("M  staged.go\n M modified.go\nMM both.go\n?? new.go\nUU conflict.go\n!! ignored.log\n")
+			Expect(status).To(Equal(Status{Staged: 2, Unstaged: 2, Untracked: 1, Conflicts: 1}))
+			Expect(status.HasModifications()).To(BeTrue())
+		})
+
+		It("detects untracked-only changes", func() {
+			status := parseStatus("?? new.go\n")
+			Expect(status.UntrackedOnly()).To(BeTrue())
+			Expect(status.Clean()).To(BeFalse())
+		})
+	})
+
+	Describe("StashPush and StashPop", func() {
+		It("stashes tracked changes and re-applies them on another branch", func() {
+			testFile := filepath.Join(tempDir, "tracked.txt")
+			Expect(os.WriteFile(testFile, []byte("v1"), 0644)).To(Succeed())
+
+			for _, args := range [][]string{
+				{"init"},
+				{"config", "user.email", "test@example.com"},
+				{"config", "user.name", "Test User"},
+				{"add", "tracked.txt"},
+				{"commit", "-m", "initial"},
+				{"branch", "other-branch"},
+			} {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = tempDir
+				Expect(cmd.Run()).NotTo(HaveOccurred())
+			}
+
+			Expect(os.WriteFile(testFile, []byte("v2"), 0644)).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tempDir, "untracked.txt"), []byte("new"), 0644)).To(Succeed())
+
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			status, err := GetStatus(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status).To(Equal(Status{Unstaged: 1, Untracked: 1}))
+
+			commit, err := StashPush(ctx, StashMessage("DEV-1", "main"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(commit).NotTo(BeEmpty())
+
+			status, err = GetStatus(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(status.UntrackedOnly()).To(BeTrue())
+
+			Expect(SwitchBranch(ctx, "other-branch")).To(Succeed())
+			Expect(StashPop(ctx, commit)).To(Succeed())
+
+			content, err := os.ReadFile(testFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("v2"))
+		})
+
+		It("stashes nothing and leaves older stashes alone without changes", func() {
+			testFile := filepath.Join(tempDir, "tracked.txt")
+			Expect(os.WriteFile(testFile, []byte("v1"), 0644)).To(Succeed())
+
+			for _, args := range [][]string{
+				{"init"},
+				{"config", "user.email", "test@example.com"},
+				{"config", "user.name", "Test User"},
+				{"add", "tracked.txt"},
+				{"commit", "-m", "initial"},
+			} {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = tempDir
+				Expect(cmd.Run()).NotTo(HaveOccurred())
+			}
+
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			commit, err := StashPush(ctx, "empty")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(commit).To(BeEmpty())
+
+			Expect(os.WriteFile(testFile, []byte("v2"), 0644)).To(Succeed())
+			older, err := StashPush(ctx, "older")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(older).NotTo(BeEmpty())
+
+			commit, err = StashPush(ctx, "empty")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(commit).To(BeEmpty())
+
+			out, err := exec.Command("git", "stash", "list").Output()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Count(string(out), "\n")).To(Equal(1))
+		})
+	})
+
+	Describe("FetchBase and SetUpstream", func() {
+		var upstreamDir, repoDir string
+
+		run := func(dir string, args ...string) string {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = dir
+			out, err := cmd.Output()
+			Expect(err).NotTo(HaveOccurred())
+			return strings.TrimSpace(string(out))
+		}
+
+		BeforeEach(func() {
+			upstreamDir = filepath.Join(tempDir, "upstream")
+			repoDir = filepath.Join(tempDir, "repo")
+			Expect(os.Mkdir(upstreamDir, 0755)).To(Succeed())
+			run(upstreamDir, "init", "--initial-branch", "trunk")
+			run(upstreamDir, "-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "--allow-empty", "-m", "initial")
+			run(tempDir, "clone", "--quiet", "--origin", "upstream", upstreamDir, repoDir)
+		})
+
+		It("creates branches from the fetched default branch of the remote", func() {
+			// The remote moves on after the clone
+			run(upstreamDir, "-c", "user.email=test@example.com", "-c", "user.name=Test User", "commit", "--allow-empty", "-m", "second")
+			head := run(upstreamDir, "rev-parse", "HEAD")
+
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(repoDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(RemoteExists(ctx, "upstream")).To(BeTrue())
+			Expect(RemoteExists(ctx, "origin")).To(BeFalse())
+
+			base, err := FetchBase(ctx, "upstream")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(base).To(Equal("upstream/trunk"))
+
+			Expect(CreateBranch(ctx, "dev-1-fresh", base)).To(Succeed())
+			Expect(run(repoDir, "rev-parse", "dev-1-fresh")).To(Equal(head))
+
+			Expect(SetUpstream(ctx, "dev-1-fresh", "upstream")).To(Succeed())
+			Expect(GetConfig(ctx, "branch.dev-1-fresh.remote")).To(Equal("upstream"))
+			Expect(GetConfig(ctx, "branch.dev-1-fresh.merge")).To(Equal("refs/heads/dev-1-fresh"))
+		})
+
+		It("lets a plain git push publish the branch", func() {
+			bareDir := filepath.Join(tempDir, "bare.git")
+			run(tempDir, "clone", "--quiet", "--bare", upstreamDir, bareDir)
+			run(repoDir, "remote", "add", "origin", bareDir)
+			run(repoDir, "fetch", "--quiet", "origin")
+
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(repoDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(CreateBranch(ctx, "dev-1-push", "origin/trunk")).To(Succeed())
+			Expect(SetUpstream(ctx, "dev-1-push", "origin")).To(Succeed())
+			Expect(SwitchBranch(ctx, "dev-1-push")).To(Succeed())
+
+			run(repoDir, "-c", "push.default=simple", "-c", "push.autoSetupRemote=false", "push", "--quiet")
+			Expect(run(bareDir, "rev-parse", "dev-1-push")).To(Equal(run(repoDir, "rev-parse", "HEAD")))
+			Expect(run(repoDir, "rev-parse", "--abbrev-ref", "@{upstream}")).To(Equal("origin/dev-1-push"))
+		})
+
+		It("fails for unknown remotes", func() {
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(repoDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = FetchBase(ctx, "origin")
+			Expect(err).To(MatchError(ContainSubstring("no remote named")))
+		})
+	})
+
+	Describe("GetRepoState", func() {
+		var oldCwd string
+
+		git := func(args ...string) error {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = tempDir
+			return cmd.Run()
+		}
+
+		BeforeEach(func() {
+			for _, args := range [][]string{
+				{"init"},
+				{"config", "user.email", "test@example.com"},
+				{"config", "user.name", "Test User"},
+			} {
+				Expect(git(args...)).To(Succeed())
+			}
+
+			var err error
+			oldCwd, err = os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.Chdir(tempDir)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			os.Chdir(oldCwd)
+		})
+
+		It("detects a branch without commits", func() {
+			state, err := GetRepoState(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(RepoState{Unborn: true}))
+			Expect(state.Check()).To(MatchError(ErrUnbornBranch))
+		})
+
+		It("detects a detached HEAD", func() {
+			Expect(git("commit", "--allow-empty", "-m", "initial")).To(Succeed())
+
+			state, err := GetRepoState(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(RepoState{}))
+			Expect(state.Check()).To(Succeed())
+			Expect(state.Warning()).To(BeEmpty())
+
+			Expect(git("checkout", "--detach")).To(Succeed())
+			state, err = GetRepoState(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state).To(Equal(RepoState{Detached: true}))
+			Expect(state.Check()).To(Succeed())
+			Expect(state.Warning()).To(ContainSubstring("detached"))
+		})
+
+		It("detects a merge stopped by conflicts", func() {
+			Expect(os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("v1"), 0644)).To(Succeed())
+			Expect(git("add", "file.txt")).To(Succeed())
+			Expect(git("commit", "-m", "initial")).To(Succeed())
+			Expect(git("checkout", "-b", "feature-branch")).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("feature"), 0644)).To(Succeed())
+			Expect(git("commit", "-am", "feature")).To(Succeed())
+			Expect(git("checkout", "-")).To(Succeed())
+			Expect(os.WriteFile(filepath.Join(tempDir, "file.txt"), []byte("main"), 0644)).To(Succeed())
+			Expect(git("commit", "-am", "main")).To(Succeed())
+			Expect(git("merge", "feature-branch")).NotTo(Succeed())
+
+			state, err := GetRepoState(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Operation).To(Equal(OpMerge))
+			Expect(state.Check()).To(MatchError(ErrOperationInProgress))
+			Expect(state.Check().Error()).To(ContainSubstring("git merge --abort"))
+
+			Expect(git("merge", "--abort")).To(Succeed())
+			Expect(git("rebase", "feature-branch")).NotTo(Succeed())
+
+			state, err = GetRepoState(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Operation).To(Equal(OpRebase))
+			Expect(state.Detached).To(BeTrue())
+			Expect(state.Check().Error()).To(ContainSubstring("git rebase --continue"))
+		})
+
+		It("detects a bisect", func() {
+			Expect(git("commit", "--allow-empty", "-m", "initial")).To(Succeed())
+			Expect(git("bisect", "start")).To(Succeed())
+
+			state, err := GetRepoState(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.Operation).To(Equal(OpBisect))
+			Expect(state.Check().Error()).To(ContainSubstring("git bisect reset"))
+		})
+	})
+
+	Describe("StackRoot and StackOrder", func() {
+		parents := parseStackParents(`branch.dev-2-api.linear-parent dev-1-schema
+branch.dev-3-ui.linear-parent dev-2-api
+branch.dev-4-docs.linear-parent dev-1-schema
+branch.release.v2.linear-parent main
+`)
+
+		It("parses branch names containing dots", func() {
+			Expect(parents).To(HaveKeyWithValue("release.v2", "main"))
+		})
+
+		It("finds the bottom of the stack", func() {
+			Expect(StackRoot(parents, "dev-3-ui")).To(Equal("dev-1-schema"))
+			Expect(StackRoot(parents, "dev-1-schema")).To(Equal("dev-1-schema"))
+		})
+
+		It("orders branches after their parents", func() {
+			Expect(StackOrder(parents, "dev-1-schema")).To(Equal([]string{"dev-2-api", "dev-4-docs", "dev-3-ui"}))
+			Expect(StackOrder(parents, "dev-3-ui")).To(BeEmpty())
+		})
+
+		It("stops on cycles", func() {
+			cycle := map[string]string{"a": "b", "b": "a"}
+			Expect(StackRoot(cycle, "a")).To(Equal("a"))
+			Expect(StackOrder(cycle, "a")).To(Equal([]string{"b"}))
+		})
+	})
+
+	Describe("Rebase", func() {
+		It("moves a stacked branch onto its rewritten parent", func() {
+			run := func(args ...string) string {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = tempDir
+				out, err := cmd.Output()
+				Expect(err).NotTo(HaveOccurred())
+				return strings.TrimSpace(string(out))
+			}
+			commit := func(file, msg string) {
+				Expect(os.WriteFile(filepath.Join(tempDir, file), []byte(msg), 0644)).To(Succeed())
+				run("add", file)
+				run("commit", "-m", msg)
+			}
+
+			run("init")
+			run("config", "user.email", "test@example.com")
+			run("config", "user.name", "Test User")
+			commit("base.txt", "initial")
+			run("checkout", "-b", "dev-1-parent")
+			commit("parent.txt", "parent")
+			run("checkout", "-b", "dev-2-child")
+			commit("child.txt", "child")
+
+			// Rewrite the parent after the child was stacked on it
+			run("checkout", "dev-1-parent")
+			Expect(os.WriteFile(filepath.Join(tempDir, "parent.txt"), []byte("amended"), 0644)).To(Succeed())
+			run("commit", "--all", "--amend", "-m", "parent amended")
+
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(SetParent(ctx, "dev-2-child", "dev-1-parent")).To(Succeed())
+			parents, err := StackParents(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(parents).To(Equal(map[string]string{"dev-2-child": "dev-1-parent"}))
+
+			Expect(Rebase(ctx, "dev-2-child", "dev-1-parent")).To(Succeed())
+			Expect(run("rev-parse", "dev-2-child~1")).To(Equal(run("rev-parse", "dev-1-parent")))
+			Expect(run("log", "--format=%s", "dev-2-child")).To(Equal("child\nparent amended\ninitial"))
+		})
+	})
+
+	Describe("parseRefs", func() {
+		It("splits local and remote-tracking branches", func() {
+			refs := parseRefs(`refs/heads/dev-1-fix
+refs/heads/feature/dev-2-api
+refs/remotes/origin/HEAD
+refs/remotes/origin/dev-1-fix
+refs/remotes/upstream/feature/dev-3-ui
+refs/remotes/team/mirror/dev-4-docs
+`, []string{"origin", "team/mirror"})
+			Expect(refs).To(Equal([]Ref{
+				{Name: "refs/heads/dev-1-fix", Branch: "dev-1-fix"},
+				{Name: "refs/heads/feature/dev-2-api", Branch: "feature/dev-2-api"},
+				{Name: "refs/remotes/origin/dev-1-fix", Branch: "dev-1-fix", Remote: "origin"},
+				{Name: "refs/remotes/upstream/feature/dev-3-ui", Branch: "feature/dev-3-ui", Remote: "upstream"},
+				{Name: "refs/remotes/team/mirror/dev-4-docs", Branch: "dev-4-docs", Remote: "team/mirror"},
+			}))
+			Expect(refs[3].String()).To(Equal("upstream/feature/dev-3-ui"))
+		})
+	})
+
+	Describe("RefSnapshot", func() {
+		refs := []Ref{
+			{Name: "refs/heads/dev-1-fix", Branch: "dev-1-fix"},
+			{Name: "refs/heads/DEV-2-api", Branch: "DEV-2-api"},
+			{Name: "refs/heads/main", Branch: "main"},
+			{Name: "refs/remotes/origin/dev-1-fix", Branch: "dev-1-fix", Remote: "origin"},
+			{Name: "refs/remotes/origin/dev-1-other", Branch: "dev-1-other", Remote: "origin"},
+		}
+
+		It("indexes branches by issue identifier", func() {
+			s := NewRefSnapshot(refs)
+			Expect(s.Find("DEV-1")).To(Equal([]Ref{refs[0], refs[4]}))
+			Expect(s.Find("dev-2")).To(Equal([]Ref{refs[1]}))
+			Expect(s.Has("DEV-3")).To(BeFalse())
+		})
+
+		It("treats a missing snapshot as empty", func() {
+			var s *RefSnapshot
+			Expect(s.Has("DEV-1")).To(BeFalse())
+		})
+	})
+
+	Describe("FindIssueBranches", func() {
+		It("matches branches by identifier whatever their suffix", func() {
+			for _, args := range [][]string{
+				{"init"},
+				{"config", "user.email", "test@example.com"},
+				{"config", "user.name", "Test User"},
+				{"commit", "--allow-empty", "-m", "initial"},
+				{"branch", "dev-123-edited-suffix"},
+				{"branch", "someone/DEV-123-other-attempt"},
+				{"branch", "dev-1234-unrelated"},
+				{"update-ref", "refs/remotes/origin/dev-123-edited-suffix", "HEAD"},
+				{"update-ref", "refs/remotes/origin/dev-123-remote-only", "HEAD"},
+			} {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = tempDir
+				Expect(cmd.Run()).NotTo(HaveOccurred())
+			}
+
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			matches, err := FindIssueBranches(ctx, "dev-123")
+			Expect(err).NotTo(HaveOccurred())
+			names := make([]string, len(matches))
+			for i, ref := range matches {
+				names[i] = ref.String()
+			}
+			Expect(names).To(Equal([]string{
+				"dev-123-edited-suffix",
+				"someone/DEV-123-other-attempt",
+				"origin/dev-123-remote-only",
+			}))
+		})
+	})
+
+	Describe("CreateTrackingBranch", func() {
+		It("checks out remote-only branches explicitly", func() {
+			for _, args := range [][]string{
+				{"init"},
+				{"config", "user.email", "test@example.com"},
+				{"config", "user.name", "Test User"},
+				{"commit", "--allow-empty", "-m", "initial"},
+				{"remote", "add", "origin", "https://example.com/repo.git"},
+				{"update-ref", "refs/remotes/origin/feature/dev-7-remote", "HEAD"},
+			} {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = tempDir
+				Expect(cmd.Run()).NotTo(HaveOccurred())
+			}
+
+			oldCwd, err := os.Getwd()
+			Expect(err).NotTo(HaveOccurred())
+			defer os.Chdir(oldCwd)
+
+			err = os.Chdir(tempDir)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(LocalBranchExists(ctx, "feature/dev-7-remote")).To(BeFalse())
+
+			// No guessing from the remote-tracking branch
+			Expect(SwitchBranch(ctx, "feature/dev-7-remote")).NotTo(Succeed())
+
+			matches, err := FindIssueBranches(ctx, "DEV-7")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(matches).To(HaveLen(1))
+			Expect(matches[0].Local()).To(BeFalse())
+
+			Expect(CreateTrackingBranch(ctx, matches[0])).To(Succeed())
+			Expect(SwitchBranch(ctx, "feature/dev-7-remote")).To(Succeed())
+			Expect(GetConfig(ctx, "branch.feature/dev-7-remote.merge")).To(Equal("refs/heads/feature/dev-7-remote"))
+		})
+	})
+
+	DescribeTable("classify",
+		func(stderr string, expected error) {
+			if expected == nil {
+				Expect(classify(stderr)).To(BeNil())
+				return
+			}
+			Expect(classify(stderr)).To(Equal(expected))
+		},
+		Entry("not a repository", "fatal: not a git repository (or any of the parent directories): .git\n", ErrNotRepository),
+		Entry("index lock", "fatal: Unable to create '/repo/.git/index.lock': File exists.\n", ErrIndexLocked),
+		Entry("local changes", "error: Your local changes to the following files would be overwritten by checkout:\n\tfile.txt\n", ErrWouldOverwrite),
+		Entry("existing branch", "fatal: a branch named 'dev-1' already exists\n", ErrRefExists),
+		Entry("invalid branch name", "fatal: 'dev..1' is not a valid branch name\n", ErrInvalidBranchName),
+		Entry("unknown revision", "fatal: not a valid object name: 'nope'\n", ErrUnknownRevision),
+		Entry("unknown branch", "fatal: invalid reference: nope\n", ErrUnknownRevision),
+		Entry("unrecognized", "fatal: unable to access remote\n", nil),
+	)
 })
@@ -0,0 +1,462 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/config"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+
+	"github.com/metalgrid/git-linear/internal/branch"
+)
+
+// goGitRepository implements Repository in-process with go-git
+type goGitRepository struct {
+	repo *gogit.Repository
+	// root is the top-level directory of the opened worktree
+	root string
+}
+
+// NewGoGitRepository opens the repository containing path with go-git.
+func NewGoGitRepository(path string) (Repository, error) {
+	repo, err := gogit.PlainOpenWithOptions(path, &gogit.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
+	if errors.Is(err, gogit.ErrRepositoryNotExists) {
+		return nil, ErrNotRepository
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open repository: %w", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open worktree: %w", err)
+	}
+	return &goGitRepository{repo: repo, root: wt.Filesystem.Root()}, nil
+}
+
+// unsupported reports an operation go-git cannot perform
+func unsupported(op string) error {
+	return fmt.Errorf("%s with the %s backend: %w", op, BackendGoGit, errors.ErrUnsupported)
+}
+
+func (r *goGitRepository) GetStatus(ctx context.Context) (Status, error) {
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return Status{}, err
+	}
+	status, err := wt.StatusWithOptions(gogit.StatusOptions{Strategy: gogit.Preload})
+	if err != nil {
+		return Status{}, err
+	}
+	// go-git renders its status in the porcelain format
+	return parseStatus(status.String()), nil
+}
+
+func (r *goGitRepository) GetCurrentBranch(ctx context.Context) (string, error) {
+	head, err := r.repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return "", err
+	}
+	// Like git rev-parse --abbrev-ref, a detached HEAD is reported as HEAD
+	if head.Type() != plumbing.SymbolicReference {
+		return "HEAD", nil
+	}
+	return head.Target().Short(), nil
+}
+
+func (r *goGitRepository) GetDefaultBranch(ctx context.Context) (string, error) {
+	if name, ok := r.remoteHead(DefaultRemote); ok {
+		return name, nil
+	}
+	for _, name := range []string{"main", "master"} {
+		if _, err := r.repo.Reference(plumbing.NewBranchReferenceName(name), false); err == nil {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("could not determine default branch")
+}
+
+// remoteHead returns the branch the HEAD of a remote points at
+func (r *goGitRepository) remoteHead(remote string) (string, bool) {
+	ref, err := r.repo.Storer.Reference(plumbing.NewRemoteHEADReferenceName(remote))
+	if err != nil || ref.Type() != plumbing.SymbolicReference {
+		return "", false
+	}
+	return strings.TrimPrefix(ref.Target().String(), "refs/remotes/"+remote+"/"), true
+}
+
+func (r *goGitRepository) SnapshotRefs(ctx context.Context) (*RefSnapshot, error) {
+	remotes, err := r.repo.Remotes()
+	if err != nil {
+		return nil, err
+	}
+	remoteNames := make([]string, len(remotes))
+	for i, remote := range remotes {
+		remoteNames[i] = remote.Config().Name
+	}
+
+	iter, err := r.repo.References()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Name().IsBranch() || ref.Name().IsRemote() {
+			names = append(names, ref.Name().String())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Match the order of git for-each-ref
+	sort.Strings(names)
+	return NewRefSnapshot(parseRefs(strings.Join(names, "\n"), remoteNames)), nil
+}
+
+func (r *goGitRepository) ListLocalBranches(ctx context.Context) ([]string, error) {
+	iter, err := r.repo.Branches()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		names = append(names, ref.Name().Short())
+		return nil
+	})
+	sort.Strings(names)
+	return names, err
+}
+
+func (r *goGitRepository) CreateBranch(ctx context.Context, name, base string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// go-git would write any name, git refuses the same ones as Validate
+	if err := branch.Validate(name); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidBranchName, err)
+	}
+	hash, err := r.repo.ResolveRevision(plumbing.Revision(base))
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrUnknownRevision, base, err)
+	}
+	return r.createRef(plumbing.NewBranchReferenceName(name), *hash)
+}
+
+// createRef creates a branch, refusing to overwrite an existing one. The
+// store cannot express "must not exist" (CheckAndSetReference skips the check
+// for a nil old reference), so like git the ref is written to a lock file
+// created exclusively and renamed into place while it is held.
+func (r *goGitRepository) createRef(name plumbing.ReferenceName, hash plumbing.Hash) error {
+	ref := plumbing.NewHashReference(name, hash)
+	storage, ok := r.repo.Storer.(*filesystem.Storage)
+	if !ok {
+		if _, err := r.repo.Reference(name, false); err == nil {
+			return fmt.Errorf("%w: %s", ErrRefExists, name.Short())
+		}
+		return r.repo.Storer.CheckAndSetReference(ref, nil)
+	}
+
+	dotGit := storage.Filesystem()
+	lock := name.String() + ".lock"
+	f, err := dotGit.OpenFile(lock, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s is locked by another git process (%s exists)", name.Short(), lock)
+	}
+	if err != nil {
+		return err
+	}
+	defer func() { _ = dotGit.Remove(lock) }()
+
+	_, err = fmt.Fprintln(f, hash)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	// Nobody else can create the ref while the lock is held
+	if _, err := r.repo.Reference(name, false); err == nil {
+		return fmt.Errorf("%w: %s", ErrRefExists, name.Short())
+	}
+	return dotGit.Rename(lock, name.String())
+}
+
+func (r *goGitRepository) CreateTrackingBranch(ctx context.Context, ref Ref) error {
+	if ref.Local() {
+		return fmt.Errorf("%s is not a remote-tracking branch", ref)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	remote, err := r.repo.Reference(plumbing.ReferenceName(ref.Name), true)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	if err := r.createRef(plumbing.NewBranchReferenceName(ref.Branch), remote.Hash()); err != nil {
+		return err
+	}
+	return r.SetUpstream(ctx, ref.Branch, ref.Remote)
+}
+
+func (r *goGitRepository) SwitchBranch(ctx context.Context, name string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	// go-git cannot carry changes over like git checkout does: it moves
+	// HEAD before it refuses to reset a dirty tree, and would lose staged
+	// changes. Refuse before touching anything.
+	wt, err := r.repo.Worktree()
+	if err != nil {
+		return err
+	}
+	status, err := wt.StatusWithOptions(gogit.StatusOptions{Strategy: gogit.Preload})
+	if err != nil {
+		return err
+	}
+	if summary := parseStatus(status.String()); summary.HasModifications() {
+		return fmt.Errorf("%w: %s (the go-git backend cannot carry changes over)", ErrWouldOverwrite, summary)
+	}
+	// Unlike git, go-git silently replaces untracked files with the
+	// branch's version
+	clobbered, err := r.untrackedInBranch(status, name)
+	if err != nil {
+		return err
+	}
+	if len(clobbered) > 0 {
+		return fmt.Errorf("%w: untracked files would be overwritten by checkout: %s", ErrWouldOverwrite, strings.Join(clobbered, ", "))
+	}
+
+	err = wt.Checkout(&gogit.CheckoutOptions{
+		Branch: plumbing.NewBranchReferenceName(name),
+	})
+	if errors.Is(err, gogit.ErrUnstagedChanges) {
+		return fmt.Errorf("%w: %w", ErrWouldOverwrite, err)
+	}
+	return err
+}
+
+// untrackedInBranch returns the untracked files that the tree of a branch
+// has a file at, or at one of their parent directories
+func (r *goGitRepository) untrackedInBranch(status gogit.Status, name string) ([]string, error) {
+	ref, err := r.repo.Reference(plumbing.NewBranchReferenceName(name), true)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := r.repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var clobbered []string
+	for path, s := range status {
+		if s.Worktree != gogit.Untracked {
+			continue
+		}
+		for p := path; p != "." && p != "/"; p = filepath.Dir(p) {
+			entry, err := tree.FindEntry(filepath.ToSlash(p))
+			if err == nil && entry.Mode.IsFile() {
+				clobbered = append(clobbered, path)
+				break
+			}
+		}
+	}
+	sort.Strings(clobbered)
+	return clobbered, nil
+}
+
+func (r *goGitRepository) EnsureWorktree(ctx context.Context, layout, name, base string) (string, bool, error) {
+	worktrees, err := r.listWorktrees()
+	if err != nil {
+		return "", false, err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == name {
+			return wt.Path, false, nil
+		}
+	}
+	return "", false, unsupported("creating worktrees")
+}
+
+func (r *goGitRepository) OtherWorktree(ctx context.Context, name string) (string, bool, error) {
+	worktrees, err := r.listWorktrees()
+	if err != nil {
+		return "", false, err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == name && filepath.Clean(wt.Path) != filepath.Clean(r.root) {
+			return wt.Path, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// listWorktrees reads the worktrees from the administrative files of the
+// repository, as go-git does not know about linked worktrees
+func (r *goGitRepository) listWorktrees() ([]Worktree, error) {
+	common, err := r.commonDir()
+	if err != nil {
+		return nil, err
+	}
+
+	main := Worktree{Path: filepath.Dir(common)}
+	main.Branch, main.Detached = readHead(filepath.Join(common, "HEAD"))
+	worktrees := []Worktree{main}
+
+	entries, err := os.ReadDir(filepath.Join(common, "worktrees"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return worktrees, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		admin := filepath.Join(common, "worktrees", entry.Name())
+		gitdir, err := os.ReadFile(filepath.Join(admin, "gitdir"))
+		if err != nil {
+			continue
+		}
+		wt := Worktree{Path: filepath.Dir(strings.TrimSpace(string(gitdir)))}
+		wt.Branch, wt.Detached = readHead(filepath.Join(admin, "HEAD"))
+		worktrees = append(worktrees, wt)
+	}
+	return worktrees, nil
+}
+
+// commonDir returns the git directory shared by all worktrees
+func (r *goGitRepository) commonDir() (string, error) {
+	dotGit := filepath.Join(r.root, ".git")
+	info, err := os.Stat(dotGit)
+	if err != nil {
+		return "", err
+	}
+	if info.IsDir() {
+		return dotGit, nil
+	}
+
+	// A linked worktree has a .git file pointing at its administrative
+	// directory, which in turn points at the common directory
+	content, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+	admin := strings.TrimSpace(strings.TrimPrefix(string(content), "gitdir:"))
+	if !filepath.IsAbs(admin) {
+		admin = filepath.Join(r.root, admin)
+	}
+	common, err := os.ReadFile(filepath.Join(admin, "commondir"))
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(string(common))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(admin, dir)
+	}
+	return filepath.Clean(dir), nil
+}
+
+// readHead reads the branch checked out according to a HEAD file
+func readHead(path string) (branch string, detached bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	target, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "ref: ")
+	if !ok {
+		return "", true
+	}
+	return strings.TrimPrefix(target, "refs/heads/"), false
+}
+
+func (r *goGitRepository) RemoteExists(ctx context.Context, remote string) bool {
+	_, err := r.repo.Remote(remote)
+	return err == nil
+}
+
+func (r *goGitRepository) FetchBase(ctx context.Context, remote string) (string, error) {
+	if !r.RemoteExists(ctx, remote) {
+		return "", fmt.Errorf("no remote named %q", remote)
+	}
+	name, ok := r.remoteHead(remote)
+	if !ok {
+		for _, candidate := range []string{"main", "master"} {
+			if _, err := r.repo.Reference(plumbing.NewRemoteReferenceName(remote, candidate), false); err == nil {
+				name, ok = candidate, true
+				break
+			}
+		}
+	}
+	if !ok {
+		return "", fmt.Errorf("could not determine default branch of %s", remote)
+	}
+
+	refSpec := gitconfig.RefSpec(fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", name, remote, name))
+	err := r.repo.FetchContext(ctx, &gogit.FetchOptions{
+		RemoteName: remote,
+		RefSpecs:   []gitconfig.RefSpec{refSpec},
+	})
+	if err != nil && !errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		return "", fmt.Errorf("failed to fetch %s from %s: %w", name, remote, err)
+	}
+	return remote + "/" + name, nil
+}
+
+func (r *goGitRepository) SetUpstream(ctx context.Context, name, remote string) error {
+	return r.setBranchOptions(name, "remote", remote, "merge", "refs/heads/"+name)
+}
+
+func (r *goGitRepository) SetParent(ctx context.Context, name, parent string) error {
+	return r.setBranchOptions(name, "linear-parent", parent)
+}
+
+// setBranchOptions sets options of a branch section in the repository
+// config. The raw config is edited and parsed again, as go-git drops
+// options it does not know about when writing its typed branch config.
+func (r *goGitRepository) setBranchOptions(name string, kv ...string) error {
+	cfg, err := r.repo.Config()
+	if err != nil {
+		return err
+	}
+	section := cfg.Raw.Section("branch").Subsection(name)
+	for i := 0; i+1 < len(kv); i += 2 {
+		section.SetOption(kv[i], kv[i+1])
+	}
+
+	var buf bytes.Buffer
+	if err := config.NewEncoder(&buf).Encode(cfg.Raw); err != nil {
+		return err
+	}
+	updated := gitconfig.NewConfig()
+	if err := updated.Unmarshal(buf.Bytes()); err != nil {
+		return err
+	}
+	return r.repo.SetConfig(updated)
+}
+
+func (r *goGitRepository) StashPush(ctx context.Context, message string) (string, error) {
+	return "", unsupported("stashing")
+}
+
+func (r *goGitRepository) StashPop(ctx context.Context, commit string) error {
+	return unsupported("stashing")
+}
+
+func (r *goGitRepository) KeepsChanges() bool {
+	return false
+}
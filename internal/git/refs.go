@@ -0,0 +1,164 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/metalgrid/git-linear/internal/branch"
+)
+
+// Ref is a local or remote-tracking branch
+type Ref struct {
+	// Name is the full ref name, e.g. refs/remotes/origin/dev-123-fix
+	Name string
+	// Branch is the branch name without the remote, e.g. dev-123-fix
+	Branch string
+	// Remote is the remote of a remote-tracking branch, empty for a local
+	// branch
+	Remote string
+}
+
+// Local reports whether the ref is a local branch
+func (r Ref) Local() bool {
+	return r.Remote == ""
+}
+
+// String returns the short name of the ref, e.g. origin/dev-123-fix
+func (r Ref) String() string {
+	if r.Local() {
+		return r.Branch
+	}
+	return r.Remote + "/" + r.Branch
+}
+
+// ListRemotes returns the names of the configured remotes.
+func ListRemotes(ctx context.Context) ([]string, error) {
+	out, err := run(ctx, "remote")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(out), nil
+}
+
+// ListBranchRefs returns all local and remote-tracking branches.
+func ListBranchRefs(ctx context.Context) ([]Ref, error) {
+	remotes, err := ListRemotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out, err := run(ctx, "for-each-ref", "--format=%(refname)", "refs/heads/", "refs/remotes/")
+	if err != nil {
+		return nil, err
+	}
+	return parseRefs(out, remotes), nil
+}
+
+// CreateTrackingBranch creates a local branch from a remote-tracking branch,
+// with the same name and tracking it.
+func CreateTrackingBranch(ctx context.Context, ref Ref) error {
+	if ref.Local() {
+		return fmt.Errorf("%s is not a remote-tracking branch", ref)
+	}
+	_, err := run(ctx, "branch", "--track", ref.Branch, ref.Name)
+	return err
+}
+
+// parseRefs parses full ref names, one per line. Remote names may contain
+// slashes, so remote-tracking branches are split at the longest known remote.
+func parseRefs(out string, remotes []string) []Ref {
+	var refs []Ref
+	for _, name := range strings.Fields(out) {
+		if branchName, ok := strings.CutPrefix(name, "refs/heads/"); ok {
+			refs = append(refs, Ref{Name: name, Branch: branchName})
+			continue
+		}
+		rest, ok := strings.CutPrefix(name, "refs/remotes/")
+		if !ok {
+			continue
+		}
+		remote, branchName := splitRemote(rest, remotes)
+		// The remote HEAD only points at the remote's default branch
+		if remote == "" || branchName == "" || branchName == "HEAD" {
+			continue
+		}
+		refs = append(refs, Ref{Name: name, Branch: branchName, Remote: remote})
+	}
+	return refs
+}
+
+// splitRemote splits "origin/dev-1-fix" into its remote and branch name
+func splitRemote(name string, remotes []string) (remote, branchName string) {
+	for _, r := range remotes {
+		if strings.HasPrefix(name, r+"/") && len(r) > len(remote) {
+			remote = r
+		}
+	}
+	if remote != "" {
+		return remote, strings.TrimPrefix(name, remote+"/")
+	}
+	// Refs left behind by a removed remote
+	remote, branchName, _ = strings.Cut(name, "/")
+	return remote, branchName
+}
+
+// RefSnapshot indexes the branches of the repository at one point in time
+// by the Linear issue identifier in their name. Remote-tracking branches are
+// left out when a local branch of the same name exists.
+type RefSnapshot struct {
+	byIdentifier map[string][]Ref
+}
+
+// SnapshotRefs lists all branches with a single for-each-ref call and
+// indexes them by issue identifier.
+func SnapshotRefs(ctx context.Context) (*RefSnapshot, error) {
+	refs, err := ListBranchRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return NewRefSnapshot(refs), nil
+}
+
+// NewRefSnapshot indexes refs by issue identifier
+func NewRefSnapshot(refs []Ref) *RefSnapshot {
+	local := make(map[string]bool)
+	for _, ref := range refs {
+		if ref.Local() {
+			local[ref.Branch] = true
+		}
+	}
+
+	s := &RefSnapshot{byIdentifier: make(map[string][]Ref)}
+	for _, ref := range refs {
+		if !ref.Local() && local[ref.Branch] {
+			continue
+		}
+		if id, ok := branch.ParseIdentifier(ref.Branch); ok {
+			s.byIdentifier[id] = append(s.byIdentifier[id], ref)
+		}
+	}
+	return s
+}
+
+// Find returns the branches of an issue, whatever the rest of their name
+func (s *RefSnapshot) Find(identifier string) []Ref {
+	if s == nil {
+		return nil
+	}
+	return s.byIdentifier[strings.ToUpper(identifier)]
+}
+
+// Has reports whether the issue has any branch
+func (s *RefSnapshot) Has(identifier string) bool {
+	return len(s.Find(identifier)) > 0
+}
+
+// FindIssueBranches returns the branches of an issue from a fresh snapshot,
+// see RefSnapshot.
+func FindIssueBranches(ctx context.Context, identifier string) ([]Ref, error) {
+	s, err := SnapshotRefs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return s.Find(identifier), nil
+}
@@ -0,0 +1,77 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DefaultRemote is the remote used when none is configured
+const DefaultRemote = "origin"
+
+// RemoteExists checks if a remote with this name is configured.
+func RemoteExists(ctx context.Context, remote string) bool {
+	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", remote)
+	return cmd.Run() == nil
+}
+
+// GetRemoteDefaultBranch detects the default branch of a remote from its
+// HEAD, falling back to the common names among its remote-tracking branches.
+func GetRemoteDefaultBranch(ctx context.Context, remote string) (string, error) {
+	prefix := "refs/remotes/" + remote + "/"
+	cmd := exec.CommandContext(ctx, "git", "symbolic-ref", prefix+"HEAD")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err == nil {
+		return strings.TrimPrefix(strings.TrimSpace(out.String()), prefix), nil
+	}
+
+	for _, name := range []string{"main", "master"} {
+		cmd := exec.CommandContext(ctx, "git", "show-ref", "--verify", "--quiet", prefix+name)
+		if err := cmd.Run(); err == nil {
+			return name, nil
+		}
+	}
+
+	return "", fmt.Errorf("could not determine default branch of %s", remote)
+}
+
+// Fetch fetches a branch from a remote, updating its remote-tracking branch.
+func Fetch(ctx context.Context, remote, name string) error {
+	_, err := run(ctx, "fetch", "--quiet", remote, name)
+	return err
+}
+
+// FetchBase fetches the default branch of a remote and returns its
+// remote-tracking branch, e.g. "origin/main", to branch from.
+func FetchBase(ctx context.Context, remote string) (string, error) {
+	if !RemoteExists(ctx, remote) {
+		return "", fmt.Errorf("no remote named %q", remote)
+	}
+	name, err := GetRemoteDefaultBranch(ctx, remote)
+	if err != nil {
+		return "", err
+	}
+	if err := Fetch(ctx, remote, name); err != nil {
+		return "", fmt.Errorf("failed to fetch %s from %s: %w", name, remote, err)
+	}
+	return remote + "/" + name, nil
+}
+
+// SetUpstream makes a branch track the branch of the same name on a remote,
+// so that a plain `git push` publishes it. The remote branch does not need
+// to exist yet; until the first push git status reports it as gone.
+func SetUpstream(ctx context.Context, name, remote string) error {
+	settings := [][2]string{
+		{"branch." + name + ".remote", remote},
+		{"branch." + name + ".merge", "refs/heads/" + name},
+	}
+	for _, kv := range settings {
+		if _, err := run(ctx, "config", kv[0], kv[1]); err != nil {
+			return fmt.Errorf("failed to set %s: %w", kv[0], err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package git
+
+import (
+	"context"
+	"fmt"
+)
+
+// Backend selects the implementation of Repository
+type Backend string
+
+const (
+	// BackendExec runs the git binary for every operation
+	BackendExec Backend = "exec"
+	// BackendGoGit works on the repository in-process with go-git. It only
+	// covers the operations of the picker; reading the configuration and
+	// the other commands still run the git binary. Stashes, carrying
+	// changes over and creating worktrees are not supported.
+	BackendGoGit Backend = "go-git"
+)
+
+// ParseBackend parses a backend name. An empty name selects BackendExec.
+func ParseBackend(name string) (Backend, error) {
+	switch Backend(name) {
+	case "", BackendExec:
+		return BackendExec, nil
+	case BackendGoGit:
+		return BackendGoGit, nil
+	}
+	return "", fmt.Errorf("unknown git backend %q (expected %q or %q)", name, BackendExec, BackendGoGit)
+}
+
+// Repository is the set of operations git-linear performs on the repository
+// it runs in
+type Repository interface {
+	// GetStatus returns a summary of the working tree changes.
+	GetStatus(ctx context.Context) (Status, error)
+	// GetCurrentBranch returns the name of the current branch.
+	GetCurrentBranch(ctx context.Context) (string, error)
+	// GetDefaultBranch detects the default branch (main or master).
+	GetDefaultBranch(ctx context.Context) (string, error)
+
+	// SnapshotRefs indexes all branches by issue identifier.
+	SnapshotRefs(ctx context.Context) (*RefSnapshot, error)
+	// ListLocalBranches returns the names of all local branches.
+	ListLocalBranches(ctx context.Context) ([]string, error)
+
+	// CreateBranch creates a new branch from base, not tracking it.
+	CreateBranch(ctx context.Context, name, base string) error
+	// CreateTrackingBranch creates a local branch tracking a remote one.
+	CreateTrackingBranch(ctx context.Context, ref Ref) error
+	// SwitchBranch switches to an existing local branch.
+	SwitchBranch(ctx context.Context, name string) error
+
+	// EnsureWorktree returns a worktree with the branch checked out,
+	// creating it from base if needed, see EnsureWorktree.
+	EnsureWorktree(ctx context.Context, layout, name, base string) (path string, created bool, err error)
+	// OtherWorktree returns the path of another worktree that has the
+	// branch checked out.
+	OtherWorktree(ctx context.Context, name string) (string, bool, error)
+
+	// RemoteExists checks if a remote with this name is configured.
+	RemoteExists(ctx context.Context, remote string) bool
+	// FetchBase fetches the default branch of a remote and returns its
+	// remote-tracking branch.
+	FetchBase(ctx context.Context, remote string) (string, error)
+	// SetUpstream makes a branch track the same branch on a remote.
+	SetUpstream(ctx context.Context, name, remote string) error
+	// SetParent records that a branch is stacked on top of parent.
+	SetParent(ctx context.Context, name, parent string) error
+
+	// StashPush stashes changes to tracked files and returns the stash
+	// commit, or an empty string if there was nothing to stash.
+	StashPush(ctx context.Context, message string) (string, error)
+	// StashPop re-applies and drops the stash with this commit.
+	StashPop(ctx context.Context, commit string) error
+	// KeepsChanges reports whether uncommitted changes can be stashed or
+	// carried over when switching branches.
+	KeepsChanges() bool
+}
+
+// Open returns the repository of the current directory using a backend.
+func Open(backend Backend) (Repository, error) {
+	switch backend {
+	case "", BackendExec:
+		return NewExecRepository(), nil
+	case BackendGoGit:
+		return NewGoGitRepository(".")
+	}
+	return nil, fmt.Errorf("unknown git backend %q", backend)
+}
+
+// execRepository implements Repository with the package functions, which
+// run the git binary in the current directory
+type execRepository struct{}
+
+// NewExecRepository returns the repository of the current directory,
+// operated on by running the git binary.
+func NewExecRepository() Repository {
+	return execRepository{}
+}
+
+func (execRepository) GetStatus(ctx context.Context) (Status, error) {
+	return GetStatus(ctx)
+}
+
+func (execRepository) GetCurrentBranch(ctx context.Context) (string, error) {
+	return GetCurrentBranch(ctx)
+}
+
+func (execRepository) GetDefaultBranch(ctx context.Context) (string, error) {
+	return GetDefaultBranch(ctx)
+}
+
+func (execRepository) SnapshotRefs(ctx context.Context) (*RefSnapshot, error) {
+	return SnapshotRefs(ctx)
+}
+
+func (execRepository) ListLocalBranches(ctx context.Context) ([]string, error) {
+	return ListLocalBranches(ctx)
+}
+
+func (execRepository) CreateBranch(ctx context.Context, name, base string) error {
+	return CreateBranch(ctx, name, base)
+}
+
+func (execRepository) CreateTrackingBranch(ctx context.Context, ref Ref) error {
+	return CreateTrackingBranch(ctx, ref)
+}
+
+func (execRepository) SwitchBranch(ctx context.Context, name string) error {
+	return SwitchBranch(ctx, name)
+}
+
+func (execRepository) EnsureWorktree(ctx context.Context, layout, name, base string) (string, bool, error) {
+	return EnsureWorktree(ctx, layout, name, base)
+}
+
+func (execRepository) OtherWorktree(ctx context.Context, name string) (string, bool, error) {
+	return OtherWorktree(ctx, name)
+}
+
+func (execRepository) RemoteExists(ctx context.Context, remote string) bool {
+	return RemoteExists(ctx, remote)
+}
+
+func (execRepository) FetchBase(ctx context.Context, remote string) (string, error) {
+	return FetchBase(ctx, remote)
+}
+
+func (execRepository) SetUpstream(ctx context.Context, name, remote string) error {
+	return SetUpstream(ctx, name, remote)
+}
+
+func (execRepository) SetParent(ctx context.Context, name, parent string) error {
+	return SetParent(ctx, name, parent)
+}
+
+func (execRepository) StashPush(ctx context.Context, message string) (string, error) {
+	return StashPush(ctx, message)
+}
+
+func (execRepository) StashPop(ctx context.Context, commit string) error {
+	return StashPop(ctx, commit)
+}
+
+func (execRepository) KeepsChanges() bool {
+	return true
+}
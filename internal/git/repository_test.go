@@ -0,0 +1,173 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Repository", func() {
+	for _, backend := range []Backend{BackendExec, BackendGoGit} {
+		Describe(string(backend), func() {
+			var (
+				tempDir string
+				oldCwd  string
+				base    string
+				repo    Repository
+				ctx     context.Context
+			)
+
+			run := func(args ...string) string {
+				cmd := exec.Command("git", args...)
+				cmd.Dir = tempDir
+				out, err := cmd.Output()
+				Expect(err).NotTo(HaveOccurred())
+				return strings.TrimSpace(string(out))
+			}
+
+			BeforeEach(func() {
+				ctx = context.Background()
+
+				var err error
+				tempDir, err = os.MkdirTemp("", "repository-test-*")
+				Expect(err).NotTo(HaveOccurred())
+				tempDir, err = filepath.EvalSymlinks(tempDir)
+				Expect(err).NotTo(HaveOccurred())
+				tempDir = filepath.Join(tempDir, "repo")
+				Expect(os.Mkdir(tempDir, 0755)).To(Succeed())
+
+				Expect(os.WriteFile(filepath.Join(tempDir, "tracked.txt"), []byte("v1"), 0644)).To(Succeed())
+				run("init")
+				run("config", "user.email", "test@example.com")
+				run("config", "user.name", "Test User")
+				run("add", "tracked.txt")
+				run("commit", "-m", "initial")
+				run("branch", "dev-1-fix")
+				run("remote", "add", "origin", "https://example.com/repo.git")
+				run("update-ref", "refs/remotes/origin/dev-5-remote", "HEAD")
+				base = run("rev-parse", "--abbrev-ref", "HEAD")
+
+				oldCwd, err = os.Getwd()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(os.Chdir(tempDir)).To(Succeed())
+
+				repo, err = Open(backend)
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				os.Chdir(oldCwd)
+				os.RemoveAll(filepath.Dir(tempDir))
+			})
+
+			It("reports branches and status", func() {
+				Expect(repo.GetCurrentBranch(ctx)).To(Equal(base))
+				Expect(repo.GetDefaultBranch(ctx)).To(Equal(base))
+				Expect(repo.ListLocalBranches(ctx)).To(ConsistOf(base, "dev-1-fix"))
+				Expect(repo.RemoteExists(ctx, "origin")).To(BeTrue())
+				Expect(repo.RemoteExists(ctx, "upstream")).To(BeFalse())
+
+				Expect(repo.CreateBranch(ctx, "dev-2..bad", base)).To(MatchError(ErrInvalidBranchName))
+				Expect(repo.CreateBranch(ctx, "dev-2-ok", "no-such-base")).To(MatchError(ErrUnknownRevision))
+
+				Expect(os.WriteFile(filepath.Join(tempDir, "tracked.txt"), []byte("v2"), 0644)).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tempDir, "untracked.txt"), []byte("new"), 0644)).To(Succeed())
+				Expect(repo.GetStatus(ctx)).To(Equal(Status{Unstaged: 1, Untracked: 1}))
+
+				refs, err := repo.SnapshotRefs(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(refs.Find("DEV-1")).To(Equal([]Ref{{Name: "refs/heads/dev-1-fix", Branch: "dev-1-fix"}}))
+				Expect(refs.Find("DEV-5")).To(Equal([]Ref{{Name: "refs/remotes/origin/dev-5-remote", Branch: "dev-5-remote", Remote: "origin"}}))
+			})
+
+			It("creates and switches branches", func() {
+				Expect(repo.CreateBranch(ctx, "dev-2-new", base)).To(Succeed())
+				Expect(repo.CreateBranch(ctx, "dev-2-new", base)).To(MatchError(ErrRefExists))
+
+				// Only the git binary carries uncommitted changes over
+				if backend == BackendExec {
+					Expect(os.WriteFile(filepath.Join(tempDir, "tracked.txt"), []byte("v2"), 0644)).To(Succeed())
+					Expect(repo.SwitchBranch(ctx, "dev-2-new")).To(Succeed())
+					Expect(run("rev-parse", "--abbrev-ref", "HEAD")).To(Equal("dev-2-new"))
+					Expect(repo.GetStatus(ctx)).To(Equal(Status{Unstaged: 1}))
+				}
+
+				refs, err := repo.SnapshotRefs(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(repo.CreateTrackingBranch(ctx, refs.Find("DEV-5")[0])).To(Succeed())
+				Expect(run("rev-parse", "--abbrev-ref", "dev-5-remote@{upstream}")).To(Equal("origin/dev-5-remote"))
+			})
+
+			It("checks out the content of a diverged branch", func() {
+				run("checkout", "-b", "dev-3-diverged")
+				Expect(os.WriteFile(filepath.Join(tempDir, "tracked.txt"), []byte("diverged"), 0644)).To(Succeed())
+				run("commit", "-am", "diverge")
+				run("checkout", base)
+
+				Expect(repo.SwitchBranch(ctx, "dev-3-diverged")).To(Succeed())
+				Expect(run("rev-parse", "--abbrev-ref", "HEAD")).To(Equal("dev-3-diverged"))
+				Expect(os.ReadFile(filepath.Join(tempDir, "tracked.txt"))).To(Equal([]byte("diverged")))
+				Expect(repo.GetStatus(ctx)).To(Equal(Status{}))
+
+				// Changes that would be overwritten are refused without
+				// moving HEAD
+				Expect(os.WriteFile(filepath.Join(tempDir, "tracked.txt"), []byte("local"), 0644)).To(Succeed())
+				Expect(repo.SwitchBranch(ctx, base)).To(MatchError(ErrWouldOverwrite))
+				Expect(run("rev-parse", "--abbrev-ref", "HEAD")).To(Equal("dev-3-diverged"))
+				Expect(os.ReadFile(filepath.Join(tempDir, "tracked.txt"))).To(Equal([]byte("local")))
+			})
+
+			It("refuses to overwrite untracked files with the branch's version", func() {
+				run("checkout", "-b", "dev-3-notes")
+				Expect(os.WriteFile(filepath.Join(tempDir, "notes.txt"), []byte("committed"), 0644)).To(Succeed())
+				run("add", "notes.txt")
+				run("commit", "-m", "notes")
+				run("checkout", base)
+
+				Expect(os.WriteFile(filepath.Join(tempDir, "notes.txt"), []byte("precious"), 0644)).To(Succeed())
+				Expect(repo.SwitchBranch(ctx, "dev-3-notes")).To(MatchError(ErrWouldOverwrite))
+				Expect(run("rev-parse", "--abbrev-ref", "HEAD")).To(Equal(base))
+				Expect(os.ReadFile(filepath.Join(tempDir, "notes.txt"))).To(Equal([]byte("precious")))
+
+				// Untracked files the branch does not have are carried over
+				Expect(os.Remove(filepath.Join(tempDir, "notes.txt"))).To(Succeed())
+				Expect(os.WriteFile(filepath.Join(tempDir, "other.txt"), []byte("keep"), 0644)).To(Succeed())
+				Expect(repo.SwitchBranch(ctx, "dev-3-notes")).To(Succeed())
+				Expect(os.ReadFile(filepath.Join(tempDir, "other.txt"))).To(Equal([]byte("keep")))
+			})
+
+			It("records upstreams and parents side by side", func() {
+				Expect(repo.SetUpstream(ctx, "dev-1-fix", "origin")).To(Succeed())
+				Expect(repo.SetParent(ctx, "dev-1-fix", base)).To(Succeed())
+
+				Expect(run("config", "branch.dev-1-fix.remote")).To(Equal("origin"))
+				Expect(run("config", "branch.dev-1-fix.merge")).To(Equal("refs/heads/dev-1-fix"))
+				Expect(StackParents(ctx)).To(Equal(map[string]string{"dev-1-fix": base}))
+			})
+
+			It("finds branches checked out in other worktrees", func() {
+				path := filepath.Join(filepath.Dir(tempDir), "linked")
+				run("worktree", "add", path, "dev-1-fix")
+
+				other, found, err := repo.OtherWorktree(ctx, "dev-1-fix")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(other).To(Equal(path))
+
+				_, found, err = repo.OtherWorktree(ctx, base)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(found).To(BeFalse())
+
+				existing, created, err := repo.EnsureWorktree(ctx, "", "dev-1-fix", "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(created).To(BeFalse())
+				Expect(existing).To(Equal(path))
+			})
+		})
+	}
+})
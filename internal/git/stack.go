@@ -0,0 +1,98 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// parentKey returns the git config key recording the branch a stacked
+// branch was created from
+func parentKey(name string) string {
+	return "branch." + name + ".linear-parent"
+}
+
+// SetParent records that a branch is stacked on top of parent.
+func SetParent(ctx context.Context, name, parent string) error {
+	_, err := run(ctx, "config", parentKey(name), parent)
+	return err
+}
+
+// StackParents returns the parent of every stacked branch, keyed by branch.
+func StackParents(ctx context.Context) (map[string]string, error) {
+	out, err := run(ctx, "config", "--get-regexp", `^branch\..*\.linear-parent$`)
+	if err != nil {
+		// Exit code 1 means no branch is stacked
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+			return map[string]string{}, nil
+		}
+		return nil, err
+	}
+	return parseStackParents(out), nil
+}
+
+// parseStackParents parses the output of git config --get-regexp
+func parseStackParents(out string) map[string]string {
+	parents := make(map[string]string)
+	for _, line := range strings.Split(out, "\n") {
+		key, parent, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		name := strings.TrimSuffix(strings.TrimPrefix(key, "branch."), ".linear-parent")
+		parents[name] = parent
+	}
+	return parents
+}
+
+// StackRoot returns the bottom of the stack a branch belongs to: the first
+// ancestor, following recorded parents, that is not stacked itself.
+func StackRoot(parents map[string]string, name string) string {
+	// Bounded by the number of parents in case the config has a cycle
+	for range len(parents) {
+		parent, ok := parents[name]
+		if !ok {
+			break
+		}
+		name = parent
+	}
+	return name
+}
+
+// StackOrder returns the branches stacked on top of root, directly or
+// indirectly, with every branch after its parent. Siblings are sorted by name.
+func StackOrder(parents map[string]string, root string) []string {
+	children := make(map[string][]string)
+	for name, parent := range parents {
+		children[parent] = append(children[parent], name)
+	}
+
+	var order []string
+	seen := map[string]bool{root: true}
+	queue := []string{root}
+	for len(queue) > 0 {
+		next := children[queue[0]]
+		queue = queue[1:]
+		sort.Strings(next)
+		for _, name := range next {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			order = append(order, name)
+			queue = append(queue, name)
+		}
+	}
+	return order
+}
+
+// Rebase rebases a branch onto its parent, replaying only the commits made
+// on the branch since it forked from the parent, even if the parent has
+// been rewritten since.
+func Rebase(ctx context.Context, name, parent string) error {
+	_, err := run(ctx, "rebase", "--quiet", "--fork-point", parent, name)
+	return err
+}
@@ -0,0 +1,120 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Errors returned by RepoState.Check
+var (
+	ErrOperationInProgress = errors.New("a git operation is in progress")
+	ErrUnbornBranch        = errors.New("the current branch has no commits yet")
+)
+
+// Operation is a multi-step git command that stopped to let the user
+// resolve conflicts or, for bisect, test commits
+type Operation string
+
+// Operations detected by GetRepoState
+const (
+	OpNone       Operation = ""
+	OpRebase     Operation = "rebase"
+	OpMerge      Operation = "merge"
+	OpCherryPick Operation = "cherry-pick"
+	OpRevert     Operation = "revert"
+	OpBisect     Operation = "bisect"
+)
+
+// operationFiles maps the files git keeps in the git directory while an
+// operation is in progress to the operation. A rebase is checked first as
+// it may stop with a cherry-pick in progress.
+var operationFiles = []struct {
+	name string
+	op   Operation
+}{
+	{"rebase-merge", OpRebase},
+	{"rebase-apply", OpRebase},
+	{"MERGE_HEAD", OpMerge},
+	{"CHERRY_PICK_HEAD", OpCherryPick},
+	{"REVERT_HEAD", OpRevert},
+	{"BISECT_LOG", OpBisect},
+}
+
+// RepoState describes the state of the current worktree
+type RepoState struct {
+	Operation Operation
+	// Detached is set when HEAD points at a commit instead of a branch
+	Detached bool
+	// Unborn is set when the current branch has no commits yet
+	Unborn bool
+}
+
+// GetRepoState detects operations in progress and where HEAD points.
+func GetRepoState(ctx context.Context) (RepoState, error) {
+	var state RepoState
+
+	out, err := run(ctx, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return state, err
+	}
+	gitDir := strings.TrimSpace(out)
+	for _, f := range operationFiles {
+		if _, err := os.Stat(filepath.Join(gitDir, f.name)); err == nil {
+			state.Operation = f.op
+			break
+		}
+	}
+
+	if _, err := run(ctx, "symbolic-ref", "--quiet", "HEAD"); err != nil {
+		// Exit code 1 means HEAD is not a symbolic ref
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return state, err
+		}
+		state.Detached = true
+		return state, nil
+	}
+
+	// A branch without commits does not resolve to a commit yet
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", "HEAD")
+	state.Unborn = cmd.Run() != nil
+	return state, ctx.Err()
+}
+
+// Check returns an error explaining why branches should not be created or
+// switched in this state, or nil if it is safe to do so. A detached HEAD is
+// allowed, see Warning.
+func (s RepoState) Check() error {
+	if s.Operation != OpNone {
+		return fmt.Errorf("%w (%s). %s", ErrOperationInProgress, s.Operation, s.Operation.hint())
+	}
+	if s.Unborn {
+		return fmt.Errorf("%w. Make an initial commit first", ErrUnbornBranch)
+	}
+	return nil
+}
+
+// Warning describes a state that is safe but may surprise the user, or
+// returns an empty string
+func (s RepoState) Warning() string {
+	if s.Detached {
+		return "HEAD is detached. Commits made on it are only reachable from the reflog once you switch branches"
+	}
+	return ""
+}
+
+// hint explains how to finish or abandon an operation
+func (op Operation) hint() string {
+	switch op {
+	case OpMerge:
+		return "Commit the merge or run 'git merge --abort' first"
+	case OpBisect:
+		return "Run 'git bisect reset' when you are done first"
+	}
+	return fmt.Sprintf("Run 'git %s --continue' or 'git %s --abort' first", op, op)
+}
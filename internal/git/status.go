@@ -0,0 +1,143 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// Status summarizes the changes in the working tree.
+type Status struct {
+	Staged    int
+	Unstaged  int
+	Untracked int
+	Conflicts int
+}
+
+// Clean reports whether there are no changes at all.
+func (s Status) Clean() bool {
+	return s == Status{}
+}
+
+// HasModifications reports whether tracked files are changed. Untracked
+// files alone are carried over by a branch switch untouched.
+func (s Status) HasModifications() bool {
+	return s.Staged > 0 || s.Unstaged > 0 || s.Conflicts > 0
+}
+
+// UntrackedOnly reports whether the only changes are untracked files.
+func (s Status) UntrackedOnly() bool {
+	return s.Untracked > 0 && !s.HasModifications()
+}
+
+// String describes the changes, e.g. "2 staged, 1 modified, 3 untracked"
+func (s Status) String() string {
+	var parts []string
+	if s.Conflicts > 0 {
+		parts = append(parts, fmt.Sprintf("%d conflicted", s.Conflicts))
+	}
+	if s.Staged > 0 {
+		parts = append(parts, fmt.Sprintf("%d staged", s.Staged))
+	}
+	if s.Unstaged > 0 {
+		parts = append(parts, fmt.Sprintf("%d modified", s.Unstaged))
+	}
+	if s.Untracked > 0 {
+		parts = append(parts, fmt.Sprintf("%d untracked", s.Untracked))
+	}
+	if len(parts) == 0 {
+		return "clean"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// GetStatus returns a summary of the working tree changes.
+func GetStatus(ctx context.Context) (Status, error) {
+	out, err := run(ctx, "status", "--porcelain")
+	if err != nil {
+		return Status{}, err
+	}
+	return parseStatus(out), nil
+}
+
+// parseStatus parses the output of git status --porcelain
+func parseStatus(output string) Status {
+	var s Status
+	for _, line := range strings.Split(output, "\n") {
+		if len(line) < 2 {
+			continue
+		}
+		xy := line[:2]
+		switch {
+		case xy == "??":
+			s.Untracked++
+		case xy == "!!":
+			// Ignored files are not changes
+		case xy == "DD" || xy == "AA" || strings.Contains(xy, "U"):
+			s.Conflicts++
+		default:
+			if xy[0] != ' ' {
+				s.Staged++
+			}
+			if xy[1] != ' ' {
+				s.Unstaged++
+			}
+		}
+	}
+	return s
+}
+
+// StashMessage returns the label used for stashes created for an issue, so
+// they can be found again in git stash list.
+func StashMessage(identifier, from string) string {
+	return fmt.Sprintf("git-linear %s: changes from %s", identifier, from)
+}
+
+// StashPush stashes the changes to tracked files and returns the commit
+// of the new stash entry, or an empty string if there was nothing to stash.
+func StashPush(ctx context.Context, message string) (string, error) {
+	before, err := stashCommit(ctx)
+	if err != nil {
+		return "", err
+	}
+	// git stash exits 0 with "No local changes to save" without an entry
+	if _, err := run(ctx, "stash", "push", "--message", message); err != nil {
+		return "", err
+	}
+	after, err := stashCommit(ctx)
+	if err != nil || after == before {
+		return "", err
+	}
+	return after, nil
+}
+
+// stashCommit returns the commit of the latest stash entry, or an empty
+// string if there is none
+func stashCommit(ctx context.Context) (string, error) {
+	out, err := run(ctx, "rev-parse", "--verify", "--quiet", "refs/stash")
+	// Exit code 1 means there is no stash
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return "", nil
+	}
+	return strings.TrimSpace(out), err
+}
+
+// StashPop re-applies the stash entry with the given commit and drops it.
+// The entry is kept if applying it fails, e.g. because of conflicts.
+func StashPop(ctx context.Context, commit string) error {
+	out, err := run(ctx, "stash", "list", "--format=%H")
+	if err != nil {
+		return err
+	}
+
+	for i, hash := range strings.Split(strings.TrimSpace(out), "\n") {
+		if hash == commit {
+			_, err := run(ctx, "stash", "pop", fmt.Sprintf("stash@{%d}", i))
+			return err
+		}
+	}
+	return fmt.Errorf("stash %s not found", commit)
+}
@@ -0,0 +1,163 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// DefaultWorktreeLayout places worktrees next to the main worktree, e.g.
+// ../myrepo-worktrees/dev-123-fix-login
+const DefaultWorktreeLayout = "../{{.Repo}}-worktrees/{{.Branch}}"
+
+// Worktree describes a working tree attached to the repository.
+type Worktree struct {
+	Path     string
+	Head     string
+	Branch   string // short branch name, empty if detached
+	Bare     bool
+	Detached bool
+}
+
+// ListWorktrees returns the worktrees of the repository, starting with the
+// main worktree.
+func ListWorktrees(ctx context.Context) ([]Worktree, error) {
+	out, err := run(ctx, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	return parseWorktrees(out), nil
+}
+
+// parseWorktrees parses the output of git worktree list --porcelain
+func parseWorktrees(output string) []Worktree {
+	var worktrees []Worktree
+	for _, block := range strings.Split(strings.TrimSpace(output), "\n\n") {
+		var wt Worktree
+		for _, line := range strings.Split(block, "\n") {
+			key, value, _ := strings.Cut(strings.TrimSpace(line), " ")
+			switch key {
+			case "worktree":
+				wt.Path = value
+			case "HEAD":
+				wt.Head = value
+			case "branch":
+				wt.Branch = strings.TrimPrefix(value, "refs/heads/")
+			case "bare":
+				wt.Bare = true
+			case "detached":
+				wt.Detached = true
+			}
+		}
+		if wt.Path != "" {
+			worktrees = append(worktrees, wt)
+		}
+	}
+	return worktrees
+}
+
+// FindWorktree returns the worktree that has the branch checked out.
+func FindWorktree(ctx context.Context, name string) (Worktree, bool, error) {
+	worktrees, err := ListWorktrees(ctx)
+	if err != nil {
+		return Worktree{}, false, err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == name {
+			return wt, true, nil
+		}
+	}
+	return Worktree{}, false, nil
+}
+
+// GetTopLevel returns the root directory of the current worktree.
+func GetTopLevel(ctx context.Context) (string, error) {
+	out, err := run(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// WorktreePath expands a worktree layout template for a branch. The layout
+// may use {{.Repo}} (name of the main worktree directory) and {{.Branch}};
+// relative paths are resolved against the main worktree.
+func WorktreePath(layout, mainWorktree, name string) (string, error) {
+	if layout == "" {
+		layout = DefaultWorktreeLayout
+	}
+	tmpl, err := template.New("worktree").Option("missingkey=error").Parse(layout)
+	if err != nil {
+		return "", fmt.Errorf("invalid worktree layout %q: %w", layout, err)
+	}
+
+	var path strings.Builder
+	data := struct{ Repo, Branch string }{
+		Repo:   filepath.Base(mainWorktree),
+		Branch: name,
+	}
+	if err := tmpl.Execute(&path, data); err != nil {
+		return "", fmt.Errorf("invalid worktree layout %q: %w", layout, err)
+	}
+
+	result := filepath.FromSlash(path.String())
+	if !filepath.IsAbs(result) {
+		result = filepath.Join(mainWorktree, result)
+	}
+	return filepath.Clean(result), nil
+}
+
+// EnsureWorktree returns a worktree with the branch checked out, creating
+// it if needed. A branch already checked out in any worktree is reused. An
+// existing branch is checked out in a new worktree; otherwise the branch is
+// created from base. created reports whether a new worktree was added.
+func EnsureWorktree(ctx context.Context, layout, name, base string) (path string, created bool, err error) {
+	worktrees, err := ListWorktrees(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	if len(worktrees) == 0 {
+		return "", false, fmt.Errorf("no worktrees found")
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == name {
+			return wt.Path, false, nil
+		}
+	}
+
+	path, err = WorktreePath(layout, worktrees[0].Path, name)
+	if err != nil {
+		return "", false, err
+	}
+
+	args := []string{"worktree", "add"}
+	if LocalBranchExists(ctx, name) {
+		args = append(args, path, name)
+	} else {
+		args = append(args, "--no-track", "-b", name, path, base)
+	}
+	if _, err := run(ctx, args...); err != nil {
+		return "", false, err
+	}
+	return path, true, nil
+}
+
+// OtherWorktree returns the path of the worktree, other than the current
+// one, that has the branch checked out. Git refuses to check out such a
+// branch a second time.
+func OtherWorktree(ctx context.Context, name string) (string, bool, error) {
+	wt, found, err := FindWorktree(ctx, name)
+	if err != nil || !found {
+		return "", false, err
+	}
+	top, err := GetTopLevel(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	if filepath.Clean(wt.Path) == filepath.Clean(top) {
+		return "", false, nil
+	}
+	return wt.Path, true, nil
+}
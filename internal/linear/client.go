@@ -1,16 +1,21 @@
 package linear
 
 import (
-	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
 const (
 	defaultAPIURL = "https://api.linear.app/graphql"
 	timeout       = 30 * time.Second
+
+	defaultPageSize = 50
+	maxPageSize     = 250
 )
 
 // Client represents a Linear API client
@@ -18,6 +23,10 @@ type Client struct {
 	apiKey     string
 	apiURL     string
 	httpClient *http.Client
+
+	mu        sync.Mutex
+	retry     RetryPolicy
+	rateLimit RateLimit
 }
 
 // NewClient creates a new Linear API client with the default API URL
@@ -28,6 +37,7 @@ func NewClient(apiKey string) *Client {
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
+		retry: DefaultRetryPolicy(),
 	}
 }
 
@@ -39,126 +49,263 @@ func NewClientWithURL(apiKey, apiURL string) *Client {
 		httpClient: &http.Client{
 			Timeout: timeout,
 		},
+		retry: DefaultRetryPolicy(),
 	}
 }
 
-// graphQLRequest represents a GraphQL request
-type graphQLRequest struct {
-	Query string `json:"query"`
+// IssueQueryOptions controls how assigned issues are paginated
+type IssueQueryOptions struct {
+	// PageSize is the number of issues requested per page (defaults to 50)
+	PageSize int
+	// Limit caps the total number of issues fetched (0 means no limit)
+	Limit int
 }
 
-// graphQLResponse represents the top-level GraphQL response
-type graphQLResponse struct {
-	Data   *responseData  `json:"data"`
-	Errors []graphQLError `json:"errors,omitempty"`
+// IssueIterator walks the pages of a paginated issue query
+type IssueIterator struct {
+	ctx     context.Context
+	client  *Client
+	opts    IssueQueryOptions
+	cursor  string
+	fetched int
+	done    bool
+	page    []Issue
+	err     error
 }
 
-// graphQLError represents a GraphQL error
-type graphQLError struct {
-	Message string `json:"message"`
+// AssignedIssues returns an iterator over the viewer's open assigned issues.
+// Each call to Next fetches one page from the Linear API; canceling ctx
+// aborts the iteration.
+func (c *Client) AssignedIssues(ctx context.Context, opts IssueQueryOptions) *IssueIterator {
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultPageSize
+	}
+	if opts.PageSize > maxPageSize {
+		opts.PageSize = maxPageSize
+	}
+	return &IssueIterator{ctx: ctx, client: c, opts: opts}
 }
 
-// responseData represents the data field in the GraphQL response
-type responseData struct {
-	Viewer *viewer `json:"viewer"`
+// Next fetches the next page of issues. It returns false when there are no
+// more pages, the limit has been reached or an error occurred.
+func (it *IssueIterator) Next() bool {
+	it.page = nil
+	if it.done || it.err != nil {
+		return false
+	}
+
+	first := it.opts.PageSize
+	if it.opts.Limit > 0 && it.opts.Limit-it.fetched < first {
+		first = it.opts.Limit - it.fetched
+	}
+
+	variables := Variables{
+		"first":  first,
+		"filter": openIssuesFilter,
+	}
+	if it.cursor != "" {
+		variables["after"] = it.cursor
+	}
+
+	data, err := assignedIssuesQuery.execute(it.ctx, it.client, variables)
+	if err != nil {
+		it.err = err
+		return false
+	}
+
+	// Handle empty response
+	if data == nil || data.Viewer == nil || data.Viewer.AssignedIssues == nil {
+		it.done = true
+		return false
+	}
+
+	connection := data.Viewer.AssignedIssues
+	it.page = connection.Nodes
+	it.fetched += len(connection.Nodes)
+	it.cursor = connection.PageInfo.EndCursor
+
+	if !connection.PageInfo.HasNextPage || it.cursor == "" ||
+		(it.opts.Limit > 0 && it.fetched >= it.opts.Limit) {
+		it.done = true
+	}
+
+	return len(it.page) > 0 || !it.done
 }
 
-// viewer represents the viewer field in the GraphQL response
-type viewer struct {
-	AssignedIssues *assignedIssues `json:"assignedIssues"`
+// Issues returns the issues of the page fetched by the last call to Next
+func (it *IssueIterator) Issues() []Issue {
+	return it.page
 }
 
-// assignedIssues represents the assignedIssues field in the GraphQL response
-type assignedIssues struct {
-	Nodes []Issue `json:"nodes"`
+// More reports whether another call to Next may return more issues
+func (it *IssueIterator) More() bool {
+	return !it.done && it.err == nil
 }
 
-// GetAssignedIssues fetches assigned issues from Linear API
-func (c *Client) GetAssignedIssues() ([]Issue, error) {
-	query := `
-		query AssignedIssues {
-			viewer {
-				assignedIssues(
-					first: 50
-					filter: { state: { type: { nin: ["completed", "canceled"] } } }
-				) {
-					nodes {
-						id
-						identifier
-						title
-						state {
-							name
-							type
-						}
-					}
-				}
-			}
-		}
-	`
+// Err returns the error that stopped the iteration, if any
+func (it *IssueIterator) Err() error {
+	return it.err
+}
+
+// GetAssignedIssues fetches all open assigned issues from Linear API,
+// following pagination cursors until every page has been retrieved
+func (c *Client) GetAssignedIssues(ctx context.Context) ([]Issue, error) {
+	return c.GetAssignedIssuesWithOptions(ctx, IssueQueryOptions{})
+}
 
-	var response graphQLResponse
-	if err := c.executeQuery(query, &response); err != nil {
+// GetAssignedIssuesWithOptions fetches assigned issues using the given
+// page size and overall limit
+func (c *Client) GetAssignedIssuesWithOptions(ctx context.Context, opts IssueQueryOptions) ([]Issue, error) {
+	issues := []Issue{}
+	it := c.AssignedIssues(ctx, opts)
+	for it.Next() {
+		issues = append(issues, it.Issues()...)
+	}
+	if err := it.Err(); err != nil {
 		return nil, err
 	}
+	return issues, nil
+}
 
-	// Handle empty response
-	if response.Data == nil || response.Data.Viewer == nil || response.Data.Viewer.AssignedIssues == nil {
-		return []Issue{}, nil
+// GetIssue fetches a single issue, including its description, project,
+// cycle and assignee. id may be the issue UUID or its identifier.
+func (c *Client) GetIssue(ctx context.Context, id string) (*Issue, error) {
+	data, err := issueQuery.execute(ctx, c, Variables{"id": id})
+	if err != nil {
+		return nil, err
 	}
-
-	issues := response.Data.Viewer.AssignedIssues.Nodes
-	if issues == nil {
-		return []Issue{}, nil
+	if data == nil || data.Issue == nil {
+		return nil, fmt.Errorf("issue %s: %w", id, &APIError{Kind: ErrNotFound})
 	}
+	return data.Issue, nil
+}
 
-	return issues, nil
+// GetIssueByIdentifier fetches a single issue by its identifier (e.g. DEV-123)
+// or Linear URL
+func (c *Client) GetIssueByIdentifier(ctx context.Context, ref string) (*Issue, error) {
+	identifier, err := ParseIssueReference(ref)
+	if err != nil {
+		return nil, err
+	}
+	return c.GetIssue(ctx, identifier)
 }
 
-// ValidateAPIKey validates the API key by making a simple query
-func (c *Client) ValidateAPIKey() error {
-	_, err := c.GetAssignedIssues()
-	return err
+// GetViewer fetches the user the API key belongs to
+func (c *Client) GetViewer(ctx context.Context) (*User, error) {
+	data, err := viewerQuery.execute(ctx, c, nil)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil || data.Viewer == nil {
+		return nil, fmt.Errorf("viewer: %w", &APIError{Kind: ErrNotFound})
+	}
+	return data.Viewer, nil
 }
 
-// executeQuery executes a GraphQL query and decodes the response
-func (c *Client) executeQuery(query string, response interface{}) error {
-	reqBody := graphQLRequest{
-		Query: query,
+// GetWorkflowStates fetches the workflow states of a team, ordered by their
+// position on the team's board
+func (c *Client) GetWorkflowStates(ctx context.Context, teamID string) ([]State, error) {
+	data, err := workflowStatesQuery.execute(ctx, c, Variables{"teamId": teamID})
+	if err != nil {
+		return nil, err
 	}
+	if data == nil || data.WorkflowStates == nil {
+		return []State{}, nil
+	}
+
+	states := data.WorkflowStates.Nodes
+	sort.SliceStable(states, func(i, j int) bool {
+		return states[i].Position < states[j].Position
+	})
+	return states, nil
+}
 
-	jsonData, err := json.Marshal(reqBody)
+// UpdateIssueState moves an issue to the workflow state with the given ID
+func (c *Client) UpdateIssueState(ctx context.Context, issueID, stateID string) error {
+	data, err := issueUpdateMutation.execute(ctx, c, Variables{
+		"id":    issueID,
+		"input": map[string]any{"stateId": stateID},
+	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return err
+	}
+	if data == nil || data.IssueUpdate == nil || !data.IssueUpdate.Success {
+		return fmt.Errorf("failed to update issue %s", issueID)
+	}
+	return nil
+}
+
+// StartIssue moves an issue to the first "started" workflow state of its
+// team. Issues that are already started, completed or canceled are left
+// untouched. It returns the state the issue is in afterwards.
+func (c *Client) StartIssue(ctx context.Context, issue Issue) (State, error) {
+	switch issue.State.Type {
+	case StateTypeStarted, StateTypeCompleted, StateTypeCanceled:
+		return issue.State, nil
 	}
 
-	req, err := http.NewRequest("POST", c.apiURL, bytes.NewBuffer(jsonData))
+	states, err := c.GetWorkflowStates(ctx, issue.Team.ID)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return issue.State, err
+	}
+
+	state, ok := FirstStateOfType(states, StateTypeStarted)
+	if !ok {
+		return issue.State, fmt.Errorf("team %s has no started workflow state", issue.Team.Key)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", c.apiKey)
+	if err := c.UpdateIssueState(ctx, issue.ID, state.ID); err != nil {
+		return issue.State, err
+	}
+	return state, nil
+}
 
-	resp, err := c.httpClient.Do(req)
+// MoveIssueToState moves an issue to the workflow state of its team with
+// the given name (case-insensitive). It returns the new state.
+func (c *Client) MoveIssueToState(ctx context.Context, issue Issue, name string) (State, error) {
+	states, err := c.GetWorkflowStates(ctx, issue.Team.ID)
 	if err != nil {
-		return fmt.Errorf("failed to execute request: %w", err)
+		return issue.State, err
+	}
+
+	state, ok := FindStateByName(states, name)
+	if !ok {
+		return issue.State, fmt.Errorf("team %s has no workflow state named %q", issue.Team.Key, name)
+	}
+	if state.ID == issue.State.ID {
+		return state, nil
 	}
-	defer resp.Body.Close()
 
-	// Check for authentication errors
-	if resp.StatusCode == http.StatusUnauthorized {
-		return fmt.Errorf("authentication failed: invalid API key")
+	if err := c.UpdateIssueState(ctx, issue.ID, state.ID); err != nil {
+		return issue.State, err
 	}
+	return state, nil
+}
 
-	// Check for other HTTP errors
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// FindStateByName returns the state with the given name, ignoring case
+func FindStateByName(states []State, name string) (State, bool) {
+	for _, state := range states {
+		if strings.EqualFold(state.Name, name) {
+			return state, true
+		}
 	}
+	return State{}, false
+}
 
-	// Decode response
-	if err := json.NewDecoder(resp.Body).Decode(response); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+// FirstStateOfType returns the first state of the given type, assuming
+// states are ordered by position
+func FirstStateOfType(states []State, stateType string) (State, bool) {
+	for _, state := range states {
+		if state.Type == stateType {
+			return state, true
+		}
 	}
+	return State{}, false
+}
 
-	return nil
+// ValidateAPIKey validates the API key by making a simple query
+func (c *Client) ValidateAPIKey(ctx context.Context) error {
+	it := c.AssignedIssues(ctx, IssueQueryOptions{PageSize: 1, Limit: 1})
+	it.Next()
+	return it.Err()
 }
@@ -1,9 +1,14 @@
 package linear_test
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -20,8 +25,13 @@ var _ = Describe("Linear Client", func() {
 	var (
 		client *linear.Client
 		server *httptest.Server
+		ctx    context.Context
 	)
 
+	BeforeEach(func() {
+		ctx = context.Background()
+	})
+
 	AfterEach(func() {
 		if server != nil {
 			server.Close()
@@ -81,7 +91,7 @@ var _ = Describe("Linear Client", func() {
 			})
 
 			It("should return assigned issues", func() {
-				issues, err := client.GetAssignedIssues()
+				issues, err := client.GetAssignedIssues(ctx)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(issues).To(HaveLen(2))
 
@@ -119,7 +129,7 @@ var _ = Describe("Linear Client", func() {
 			})
 
 			It("should return empty slice", func() {
-				issues, err := client.GetAssignedIssues()
+				issues, err := client.GetAssignedIssues(ctx)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(issues).To(BeEmpty())
 				Expect(issues).NotTo(BeNil())
@@ -137,9 +147,10 @@ var _ = Describe("Linear Client", func() {
 			})
 
 			It("should return authentication error", func() {
-				issues, err := client.GetAssignedIssues()
+				issues, err := client.GetAssignedIssues(ctx)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("authentication failed"))
+				Expect(errors.Is(err, linear.ErrUnauthorized)).To(BeTrue())
 				Expect(issues).To(BeNil())
 			})
 		})
@@ -156,7 +167,7 @@ var _ = Describe("Linear Client", func() {
 			})
 
 			It("should return JSON parsing error", func() {
-				issues, err := client.GetAssignedIssues()
+				issues, err := client.GetAssignedIssues(ctx)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("failed to decode response"))
 				Expect(issues).To(BeNil())
@@ -167,13 +178,484 @@ var _ = Describe("Linear Client", func() {
 			BeforeEach(func() {
 				// Use invalid URL to simulate network error
 				client = linear.NewClientWithURL("test-api-key", "http://invalid-host-that-does-not-exist:9999")
+				client.SetRetryPolicy(linear.RetryPolicy{MaxAttempts: 1})
 			})
 
 			It("should return network error", func() {
-				issues, err := client.GetAssignedIssues()
+				issues, err := client.GetAssignedIssues(ctx)
 				Expect(err).To(HaveOccurred())
+				Expect(errors.Is(err, linear.ErrTransport)).To(BeTrue())
+				Expect(issues).To(BeNil())
+			})
+		})
+
+		Context("when API returns GraphQL errors with status 200", func() {
+			BeforeEach(func() {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusOK)
+					w.Write([]byte(`{
+						"data": null,
+						"errors": [{
+							"message": "Entity not found",
+							"extensions": {
+								"code": "ENTITY_NOT_FOUND",
+								"userPresentableMessage": "Could not find referenced issue."
+							}
+						}]
+					}`))
+				}))
+
+				client = linear.NewClientWithURL("test-api-key", server.URL)
+			})
+
+			It("should return a classified API error", func() {
+				issues, err := client.GetAssignedIssues(ctx)
 				Expect(issues).To(BeNil())
+				Expect(errors.Is(err, linear.ErrNotFound)).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("Could not find referenced issue."))
+
+				var apiErr *linear.APIError
+				Expect(errors.As(err, &apiErr)).To(BeTrue())
+				Expect(apiErr.Code()).To(Equal("ENTITY_NOT_FOUND"))
+				Expect(apiErr.Temporary()).To(BeFalse())
+			})
+		})
+
+		Context("when API reports rate limiting", func() {
+			BeforeEach(func() {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					w.WriteHeader(http.StatusBadRequest)
+					w.Write([]byte(`{"errors":[{"message":"Rate limit exceeded","extensions":{"code":"RATELIMITED"}}]}`))
+				}))
+
+				client = linear.NewClientWithURL("test-api-key", server.URL)
+				client.SetRetryPolicy(linear.RetryPolicy{MaxAttempts: 1})
+			})
+
+			It("should prefer the extension code over the status code", func() {
+				_, err := client.GetAssignedIssues(ctx)
+				Expect(errors.Is(err, linear.ErrRateLimited)).To(BeTrue())
+				Expect(errors.Is(err, linear.ErrValidation)).To(BeFalse())
+
+				var apiErr *linear.APIError
+				Expect(errors.As(err, &apiErr)).To(BeTrue())
+				Expect(apiErr.StatusCode).To(Equal(http.StatusBadRequest))
+				Expect(apiErr.Temporary()).To(BeTrue())
+			})
+		})
+
+		DescribeTable("when API returns an HTTP error status",
+			func(status int, kind error) {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(status)
+				}))
+				client = linear.NewClientWithURL("test-api-key", server.URL)
+				client.SetRetryPolicy(linear.RetryPolicy{MaxAttempts: 1})
+
+				_, err := client.GetAssignedIssues(ctx)
+				Expect(errors.Is(err, kind)).To(BeTrue())
+			},
+			Entry("403 forbidden", http.StatusForbidden, linear.ErrForbidden),
+			Entry("429 too many requests", http.StatusTooManyRequests, linear.ErrRateLimited),
+			Entry("404 not found", http.StatusNotFound, linear.ErrNotFound),
+			Entry("502 bad gateway", http.StatusBadGateway, linear.ErrServer),
+			Entry("418 teapot", http.StatusTeapot, linear.ErrUnexpected),
+		)
+	})
+
+	Describe("AssignedIssues", func() {
+		var requests []map[string]any
+
+		BeforeEach(func() {
+			requests = nil
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Query         string         `json:"query"`
+					OperationName string         `json:"operationName"`
+					Variables     map[string]any `json:"variables"`
+				}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				Expect(body.OperationName).To(Equal("AssignedIssues"))
+				Expect(body.Query).To(ContainSubstring("$after: String"))
+				requests = append(requests, body.Variables)
+
+				// Serve two pages, the second one selected by its cursor
+				w.Header().Set("Content-Type", "application/json")
+				if body.Variables["after"] == "cursor-1" {
+					fmt.Fprint(w, `{"data":{"viewer":{"assignedIssues":{
+						"nodes":[{"id":"issue-3","identifier":"GIT-3","title":"Third"}],
+						"pageInfo":{"hasNextPage":false,"endCursor":"cursor-2"}}}}}`)
+					return
+				}
+				fmt.Fprint(w, `{"data":{"viewer":{"assignedIssues":{
+					"nodes":[
+						{"id":"issue-1","identifier":"GIT-1","title":"First"},
+						{"id":"issue-2","identifier":"GIT-2","title":"Second"}
+					],
+					"pageInfo":{"hasNextPage":true,"endCursor":"cursor-1"}}}}}`)
+			}))
+
+			client = linear.NewClientWithURL("test-api-key", server.URL)
+		})
+
+		It("should yield one page per call to Next", func() {
+			it := client.AssignedIssues(ctx, linear.IssueQueryOptions{PageSize: 2})
+
+			Expect(it.Next()).To(BeTrue())
+			Expect(it.Issues()).To(HaveLen(2))
+			Expect(it.More()).To(BeTrue())
+
+			Expect(it.Next()).To(BeTrue())
+			Expect(it.Issues()).To(HaveLen(1))
+			Expect(it.Issues()[0].Identifier).To(Equal("GIT-3"))
+			Expect(it.More()).To(BeFalse())
+
+			Expect(it.Next()).To(BeFalse())
+			Expect(it.Err()).NotTo(HaveOccurred())
+			Expect(requests).To(HaveLen(2))
+			Expect(requests[0]).To(HaveKeyWithValue("first", BeNumerically("==", 2)))
+			Expect(requests[0]).NotTo(HaveKey("after"))
+			Expect(requests[0]).To(HaveKey("filter"))
+		})
+
+		It("should follow cursors in GetAssignedIssues", func() {
+			issues, err := client.GetAssignedIssues(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issues).To(HaveLen(3))
+			Expect(requests).To(HaveLen(2))
+		})
+
+		It("should stop at the configured limit", func() {
+			issues, err := client.GetAssignedIssuesWithOptions(ctx, linear.IssueQueryOptions{PageSize: 2, Limit: 2})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(issues).To(HaveLen(2))
+			Expect(requests).To(HaveLen(1))
+		})
+	})
+
+	Describe("GetIssue", func() {
+		Context("when the issue exists", func() {
+			BeforeEach(func() {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					var body struct {
+						OperationName string         `json:"operationName"`
+						Variables     map[string]any `json:"variables"`
+					}
+					Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+					Expect(body.OperationName).To(Equal("Issue"))
+					Expect(body.Variables).To(HaveKeyWithValue("id", "GIT-1"))
+
+					w.Header().Set("Content-Type", "application/json")
+					fmt.Fprint(w, `{"data":{"issue":{
+						"id": "issue-1",
+						"identifier": "GIT-1",
+						"title": "Implement Linear client",
+						"url": "https://linear.app/acme/issue/GIT-1",
+						"priority": 2,
+						"estimate": 3,
+						"updatedAt": "2026-01-02T10:00:00.000Z",
+						"createdAt": "2026-01-01T09:00:00.000Z",
+						"description": "Talk to the GraphQL API",
+						"state": {"id": "state-1", "name": "Todo", "type": "unstarted"},
+						"team": {"id": "team-1", "key": "GIT", "name": "Git"},
+						"labels": {"nodes": [{"id": "label-1", "name": "Feature"}]},
+						"project": {"id": "project-1", "name": "CLI"},
+						"cycle": {"id": "cycle-1", "number": 7, "name": "Sprint 7"},
+						"assignee": {"id": "user-1", "name": "Jane Doe", "displayName": "jane", "email": "jane@example.com"}
+					}}}`)
+				}))
+
+				client = linear.NewClientWithURL("test-api-key", server.URL)
+			})
+
+			It("should return the issue with its details", func() {
+				issue, err := client.GetIssue(ctx, "GIT-1")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(issue.Identifier).To(Equal("GIT-1"))
+				Expect(issue.URL).To(Equal("https://linear.app/acme/issue/GIT-1"))
+				Expect(issue.Priority).To(Equal(linear.PriorityHigh))
+				Expect(issue.Priority.String()).To(Equal("High"))
+				Expect(*issue.Estimate).To(Equal(3.0))
+				Expect(issue.CreatedAt).To(Equal(time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)))
+				Expect(issue.UpdatedAt).To(Equal(time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)))
+				Expect(issue.Description).To(Equal("Talk to the GraphQL API"))
+				Expect(issue.Team.Key).To(Equal("GIT"))
+				Expect(issue.Labels).To(Equal([]linear.Label{{ID: "label-1", Name: "Feature"}}))
+				Expect(issue.Project.Name).To(Equal("CLI"))
+				Expect(issue.Cycle.Number).To(Equal(7))
+				Expect(issue.Assignee.DisplayName).To(Equal("jane"))
+			})
+		})
+
+		Context("when the issue does not exist", func() {
+			BeforeEach(func() {
+				server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					fmt.Fprint(w, `{"data":{"issue":null}}`)
+				}))
+
+				client = linear.NewClientWithURL("test-api-key", server.URL)
+			})
+
+			It("should return a not found error", func() {
+				issue, err := client.GetIssue(ctx, "GIT-404")
+				Expect(issue).To(BeNil())
+				Expect(errors.Is(err, linear.ErrNotFound)).To(BeTrue())
+			})
+		})
+	})
+
+	Describe("GetViewer", func() {
+		BeforeEach(func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					OperationName string `json:"operationName"`
+				}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				Expect(body.OperationName).To(Equal("Viewer"))
+
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"data":{"viewer":{"id": "user-1", "name": "Jane Doe", "displayName": "jane", "email": "jane@example.com"}}}`)
+			}))
+
+			client = linear.NewClientWithURL("test-api-key", server.URL)
+		})
+
+		It("should return the user the API key belongs to", func() {
+			viewer, err := client.GetViewer(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(*viewer).To(Equal(linear.User{ID: "user-1", Name: "Jane Doe", DisplayName: "jane", Email: "jane@example.com"}))
+		})
+	})
+
+	DescribeTable("ParseIssueReference",
+		func(ref, expected string) {
+			identifier, err := linear.ParseIssueReference(ref)
+			if expected == "" {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(identifier).To(Equal(expected))
+		},
+		Entry("identifier", "DEV-123", "DEV-123"),
+		Entry("lowercase identifier", "dev-123", "DEV-123"),
+		Entry("issue URL", "https://linear.app/acme/issue/DEV-123/fix-login", "DEV-123"),
+		Entry("issue URL without slug", "https://linear.app/acme/issue/DEV-123", "DEV-123"),
+		Entry("branch name", "dev-123-fix-login", ""),
+		Entry("unrelated URL", "https://linear.app/acme/project/cli", ""),
+	)
+
+	Describe("StartIssue", func() {
+		var (
+			operations []string
+			updates    []map[string]any
+			issue      linear.Issue
+		)
+
+		BeforeEach(func() {
+			operations = nil
+			updates = nil
+			issue = linear.Issue{
+				ID:         "issue-1",
+				Identifier: "GIT-1",
+				State:      linear.State{ID: "state-todo", Name: "Todo", Type: "unstarted"},
+				Team:       linear.Team{ID: "team-1", Key: "GIT"},
+			}
+
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					OperationName string         `json:"operationName"`
+					Variables     map[string]any `json:"variables"`
+				}
+				Expect(json.NewDecoder(r.Body).Decode(&body)).To(Succeed())
+				operations = append(operations, body.OperationName)
+
+				w.Header().Set("Content-Type", "application/json")
+				switch body.OperationName {
+				case "WorkflowStates":
+					Expect(body.Variables).To(HaveKeyWithValue("teamId", "team-1"))
+					fmt.Fprint(w, `{"data":{"workflowStates":{"nodes":[
+						{"id":"state-review","name":"In Review","type":"started","position":3},
+						{"id":"state-todo","name":"Todo","type":"unstarted","position":1},
+						{"id":"state-progress","name":"In Progress","type":"started","position":2}
+					]}}}`)
+				case "IssueUpdate":
+					updates = append(updates, body.Variables)
+					fmt.Fprint(w, `{"data":{"issueUpdate":{"success":true,"issue":{"id":"issue-1"}}}}`)
+				}
+			}))
+
+			client = linear.NewClientWithURL("test-api-key", server.URL)
+		})
+
+		It("should move the issue to the team's first started state", func() {
+			state, err := client.StartIssue(ctx, issue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.ID).To(Equal("state-progress"))
+			Expect(state.Name).To(Equal("In Progress"))
+
+			Expect(operations).To(Equal([]string{"WorkflowStates", "IssueUpdate"}))
+			Expect(updates[0]).To(HaveKeyWithValue("id", "issue-1"))
+			Expect(updates[0]).To(HaveKeyWithValue("input", HaveKeyWithValue("stateId", "state-progress")))
+		})
+
+		It("should leave started issues untouched", func() {
+			issue.State = linear.State{ID: "state-review", Name: "In Review", Type: "started"}
+
+			state, err := client.StartIssue(ctx, issue)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(state.ID).To(Equal("state-review"))
+			Expect(operations).To(BeEmpty())
+		})
+	})
+
+	Describe("Retries", func() {
+		var attempts int
+
+		emptyPage := `{"data":{"viewer":{"assignedIssues":{"nodes":[]}}}}`
+
+		BeforeEach(func() {
+			attempts = 0
+		})
+
+		newClient := func(handler http.HandlerFunc) {
+			server = httptest.NewServer(handler)
+			client = linear.NewClientWithURL("test-api-key", server.URL)
+			client.SetRetryPolicy(linear.RetryPolicy{
+				MaxAttempts: 3,
+				BaseDelay:   time.Millisecond,
+				MaxDelay:    50 * time.Millisecond,
+			})
+		}
+
+		It("should retry transient server errors", func() {
+			newClient(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts < 3 {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				fmt.Fprint(w, emptyPage)
+			})
+
+			_, err := client.GetAssignedIssues(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attempts).To(Equal(3))
+		})
+
+		It("should give up after MaxAttempts", func() {
+			newClient(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.WriteHeader(http.StatusBadGateway)
+			})
+
+			_, err := client.GetAssignedIssues(ctx)
+			Expect(errors.Is(err, linear.ErrServer)).To(BeTrue())
+			Expect(attempts).To(Equal(3))
+		})
+
+		It("should retry rate limited requests honoring Retry-After", func() {
+			newClient(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if attempts == 1 {
+					w.Header().Set("Retry-After", "0")
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				fmt.Fprint(w, emptyPage)
+			})
+
+			_, err := client.GetAssignedIssues(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(attempts).To(Equal(2))
+		})
+
+		It("should not wait longer than MaxDelay", func() {
+			newClient(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.Header().Set("Retry-After", "60")
+				w.WriteHeader(http.StatusTooManyRequests)
+			})
+
+			_, err := client.GetAssignedIssues(ctx)
+			Expect(errors.Is(err, linear.ErrRateLimited)).To(BeTrue())
+			Expect(attempts).To(Equal(1))
+
+			var apiErr *linear.APIError
+			Expect(errors.As(err, &apiErr)).To(BeTrue())
+			Expect(apiErr.RetryAfter).To(Equal(time.Minute))
+		})
+
+		It("should stop waiting when the context is canceled", func() {
+			newClient(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			client.SetRetryPolicy(linear.RetryPolicy{
+				MaxAttempts: 3,
+				BaseDelay:   time.Minute,
+				MaxDelay:    time.Minute,
+			})
+
+			canceled, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+
+			_, err := client.GetAssignedIssues(canceled)
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			Expect(attempts).To(Equal(1))
+		})
+
+		It("should not retry mutations", func() {
+			newClient(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+
+			err := client.UpdateIssueState(ctx, "issue-1", "state-1")
+			Expect(errors.Is(err, linear.ErrServer)).To(BeTrue())
+			Expect(attempts).To(Equal(1))
+		})
+
+		It("should not retry non-transient errors", func() {
+			newClient(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.WriteHeader(http.StatusUnauthorized)
 			})
+
+			_, err := client.GetAssignedIssues(ctx)
+			Expect(errors.Is(err, linear.ErrUnauthorized)).To(BeTrue())
+			Expect(attempts).To(Equal(1))
+		})
+	})
+
+	Describe("RateLimit", func() {
+		It("should expose the budget reported in response headers", func() {
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Requests-Limit", "1500")
+				w.Header().Set("X-RateLimit-Requests-Remaining", "1499")
+				w.Header().Set("X-RateLimit-Requests-Reset", "1760000000000")
+				w.Header().Set("X-RateLimit-Complexity-Limit", "250000")
+				w.Header().Set("X-RateLimit-Complexity-Remaining", "249000")
+				w.Header().Set("X-Complexity", "1000")
+				fmt.Fprint(w, `{"data":{"viewer":{"assignedIssues":{"nodes":[]}}}}`)
+			}))
+			client = linear.NewClientWithURL("test-api-key", server.URL)
+
+			Expect(client.RateLimit()).To(Equal(linear.RateLimit{}))
+
+			_, err := client.GetAssignedIssues(ctx)
+			Expect(err).NotTo(HaveOccurred())
+
+			rl := client.RateLimit()
+			Expect(rl.RequestsLimit).To(Equal(1500))
+			Expect(rl.RequestsRemaining).To(Equal(1499))
+			Expect(rl.RequestsReset).To(Equal(time.UnixMilli(1760000000000)))
+			Expect(rl.ComplexityLimit).To(Equal(250000))
+			Expect(rl.ComplexityRemaining).To(Equal(249000))
+			Expect(rl.LastComplexity).To(Equal(1000))
 		})
 	})
 
@@ -198,7 +680,7 @@ var _ = Describe("Linear Client", func() {
 			})
 
 			It("should return no error", func() {
-				err := client.ValidateAPIKey()
+				err := client.ValidateAPIKey(ctx)
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
@@ -214,7 +696,7 @@ var _ = Describe("Linear Client", func() {
 			})
 
 			It("should return authentication error", func() {
-				err := client.ValidateAPIKey()
+				err := client.ValidateAPIKey(ctx)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("authentication failed"))
 			})
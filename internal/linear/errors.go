@@ -0,0 +1,156 @@
+package linear
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Sentinel errors classifying failures returned by the Linear API.
+// Use errors.Is to test an error against them and errors.As to
+// retrieve the underlying *APIError.
+var (
+	ErrUnauthorized = errors.New("authentication failed: invalid API key")
+	ErrForbidden    = errors.New("forbidden: API key lacks access to this resource")
+	ErrRateLimited  = errors.New("rate limited by Linear")
+	ErrNotFound     = errors.New("not found")
+	ErrValidation   = errors.New("invalid request")
+	ErrServer       = errors.New("server error")
+	ErrTransport    = errors.New("failed to execute request")
+	ErrUnexpected   = errors.New("unexpected response")
+)
+
+// Linear GraphQL error extension codes
+const (
+	codeAuthentication   = "AUTHENTICATION_ERROR"
+	codeForbidden        = "FORBIDDEN"
+	codeRateLimited      = "RATELIMITED"
+	codeEntityNotFound   = "ENTITY_NOT_FOUND"
+	codeInvalidInput     = "INVALID_INPUT"
+	codeBadUserInput     = "BAD_USER_INPUT"
+	codeValidationFailed = "GRAPHQL_VALIDATION_FAILED"
+	codeInternalError    = "INTERNAL_SERVER_ERROR"
+)
+
+// GraphQLError represents an entry of the errors array of a GraphQL response
+type GraphQLError struct {
+	Message    string            `json:"message"`
+	Path       []any             `json:"path,omitempty"`
+	Extensions GraphQLExtensions `json:"extensions"`
+}
+
+// GraphQLExtensions holds the Linear specific details of a GraphQL error
+type GraphQLExtensions struct {
+	Code                   string `json:"code"`
+	Type                   string `json:"type"`
+	UserError              bool   `json:"userError"`
+	UserPresentableMessage string `json:"userPresentableMessage"`
+}
+
+// APIError describes a failed request to the Linear API
+type APIError struct {
+	// Kind is one of the sentinel errors of this package
+	Kind error
+	// StatusCode is the HTTP status code, or 0 if no response was received
+	StatusCode int
+	// Errors holds the GraphQL errors returned by the API, if any
+	Errors []GraphQLError
+	// Err is the underlying transport error, if any
+	Err error
+	// RetryAfter is how long the server asked to wait before retrying
+	RetryAfter time.Duration
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	msg := e.Kind.Error()
+	switch {
+	case len(e.Errors) > 0:
+		messages := make([]string, len(e.Errors))
+		for i, gqlErr := range e.Errors {
+			messages[i] = gqlErr.Message
+			if gqlErr.Extensions.UserPresentableMessage != "" {
+				messages[i] = gqlErr.Extensions.UserPresentableMessage
+			}
+		}
+		msg += ": " + strings.Join(messages, "; ")
+	case e.Err != nil:
+		msg += ": " + e.Err.Error()
+	case e.StatusCode != 0 && e.StatusCode != http.StatusOK:
+		msg += fmt.Sprintf(" (status code %d)", e.StatusCode)
+	}
+	return msg
+}
+
+// Unwrap returns the error kind and the underlying transport error
+func (e *APIError) Unwrap() []error {
+	if e.Err != nil {
+		return []error{e.Kind, e.Err}
+	}
+	return []error{e.Kind}
+}
+
+// Code returns the extension code of the first GraphQL error, if any
+func (e *APIError) Code() string {
+	for _, gqlErr := range e.Errors {
+		if gqlErr.Extensions.Code != "" {
+			return gqlErr.Extensions.Code
+		}
+	}
+	return ""
+}
+
+// Temporary reports whether retrying the request may succeed
+func (e *APIError) Temporary() bool {
+	return e.Kind == ErrRateLimited || e.Kind == ErrServer || e.Kind == ErrTransport
+}
+
+// newAPIError classifies a failed response. Extension codes take precedence
+// over the HTTP status code since Linear reports some failures, such as rate
+// limiting, with a generic status.
+func newAPIError(statusCode int, gqlErrors []GraphQLError) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, Errors: gqlErrors}
+	if kind := kindFromCode(apiErr.Code()); kind != nil {
+		apiErr.Kind = kind
+		return apiErr
+	}
+
+	switch {
+	case statusCode == http.StatusUnauthorized:
+		apiErr.Kind = ErrUnauthorized
+	case statusCode == http.StatusForbidden:
+		apiErr.Kind = ErrForbidden
+	case statusCode == http.StatusTooManyRequests:
+		apiErr.Kind = ErrRateLimited
+	case statusCode == http.StatusNotFound:
+		apiErr.Kind = ErrNotFound
+	case statusCode == http.StatusBadRequest || statusCode == http.StatusUnprocessableEntity:
+		apiErr.Kind = ErrValidation
+	case statusCode >= http.StatusInternalServerError:
+		apiErr.Kind = ErrServer
+	default:
+		apiErr.Kind = ErrUnexpected
+	}
+	return apiErr
+}
+
+// kindFromCode maps a Linear extension code to a sentinel error
+func kindFromCode(code string) error {
+	switch code {
+	case codeAuthentication:
+		return ErrUnauthorized
+	case codeForbidden:
+		return ErrForbidden
+	case codeRateLimited:
+		return ErrRateLimited
+	case codeEntityNotFound:
+		return ErrNotFound
+	case codeInvalidInput, codeBadUserInput, codeValidationFailed:
+		return ErrValidation
+	case codeInternalError:
+		return ErrServer
+	}
+	return nil
+}
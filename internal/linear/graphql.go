@@ -0,0 +1,111 @@
+package linear
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Variables holds the variables of a GraphQL operation
+type Variables map[string]any
+
+// graphQLRequest represents a GraphQL request
+type graphQLRequest struct {
+	Query         string    `json:"query"`
+	OperationName string    `json:"operationName,omitempty"`
+	Variables     Variables `json:"variables,omitempty"`
+}
+
+// graphQLResponse represents the top-level GraphQL response
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []GraphQLError  `json:"errors,omitempty"`
+}
+
+// operation is a named GraphQL document whose response data decodes into D.
+// Values are passed as variables, never interpolated into the document.
+type operation[D any] struct {
+	name     string
+	document string
+	// mutation marks non-idempotent operations, which are never retried
+	mutation bool
+}
+
+// execute runs the operation with the given variables and returns its data.
+// A nil result with a nil error means the response carried no data.
+func (op operation[D]) execute(ctx context.Context, c *Client, variables Variables) (*D, error) {
+	req := graphQLRequest{
+		Query:         op.document,
+		OperationName: op.name,
+		Variables:     variables,
+	}
+	raw, err := c.executeWithRetry(ctx, req, !op.mutation)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var data D
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return &data, nil
+}
+
+// executeQuery executes a GraphQL request and returns the raw data field.
+// Failed requests and responses carrying GraphQL errors are reported as *APIError.
+func (c *Client) executeQuery(ctx context.Context, reqBody graphQLRequest) (json.RawMessage, error) {
+	jsonData, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.apiURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, &APIError{Kind: ErrTransport, Err: err}
+	}
+	defer resp.Body.Close()
+
+	c.updateRateLimit(resp.Header)
+
+	var response graphQLResponse
+	decodeErr := json.NewDecoder(resp.Body).Decode(&response)
+
+	// Non-200 responses may still carry GraphQL errors describing the failure
+	if resp.StatusCode != http.StatusOK {
+		return nil, responseError(resp, response.Errors)
+	}
+
+	if decodeErr != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", decodeErr)
+	}
+
+	if len(response.Errors) > 0 {
+		return nil, responseError(resp, response.Errors)
+	}
+
+	return response.Data, nil
+}
+
+// responseError builds the *APIError for a failed response, including the
+// delay requested by the server before retrying
+func responseError(resp *http.Response, gqlErrors []GraphQLError) *APIError {
+	apiErr := newAPIError(resp.StatusCode, gqlErrors)
+	if delay, ok := retryAfter(resp.Header, time.Now()); ok {
+		apiErr.RetryAfter = delay
+	}
+	return apiErr
+}
@@ -0,0 +1,179 @@
+package linear
+
+// issueFields is the lightweight selection set used when listing issues
+const issueFields = `
+	id
+	identifier
+	title
+	url
+	branchName
+	priority
+	estimate
+	updatedAt
+	state {
+		id
+		name
+		type
+	}
+	team {
+		id
+		key
+		name
+	}
+	labels(first: 20) {
+		nodes {
+			id
+			name
+		}
+	}
+`
+
+// issueDetailFields extends issueFields with the fields only needed once an
+// issue has been selected
+const issueDetailFields = issueFields + `
+	description
+	createdAt
+	project {
+		id
+		name
+	}
+	cycle {
+		id
+		number
+		name
+	}
+	assignee {
+		id
+		name
+		displayName
+		email
+	}
+`
+
+// openIssuesFilter excludes completed and canceled issues
+var openIssuesFilter = map[string]any{
+	"state": map[string]any{
+		"type": map[string]any{
+			"nin": []string{"completed", "canceled"},
+		},
+	},
+}
+
+// pageInfo represents the cursor information of a paginated connection
+type pageInfo struct {
+	HasNextPage bool   `json:"hasNextPage"`
+	EndCursor   string `json:"endCursor"`
+}
+
+// issueConnection represents a paginated list of issues
+type issueConnection struct {
+	Nodes    []Issue  `json:"nodes"`
+	PageInfo pageInfo `json:"pageInfo"`
+}
+
+// assignedIssuesData represents the data returned by assignedIssuesQuery
+type assignedIssuesData struct {
+	Viewer *struct {
+		AssignedIssues *issueConnection `json:"assignedIssues"`
+	} `json:"viewer"`
+}
+
+// assignedIssuesQuery fetches one page of the viewer's assigned issues
+var assignedIssuesQuery = operation[assignedIssuesData]{
+	name: "AssignedIssues",
+	document: `
+		query AssignedIssues($first: Int!, $after: String, $filter: IssueFilter) {
+			viewer {
+				assignedIssues(first: $first, after: $after, filter: $filter) {
+					nodes {` + issueFields + `}
+					pageInfo {
+						hasNextPage
+						endCursor
+					}
+				}
+			}
+		}
+	`,
+}
+
+// issueData represents the data returned by issueQuery
+type issueData struct {
+	Issue *Issue `json:"issue"`
+}
+
+// issueQuery fetches a single issue with its details. Linear accepts both
+// the issue UUID and its identifier (e.g. DEV-123) as id.
+var issueQuery = operation[issueData]{
+	name: "Issue",
+	document: `
+		query Issue($id: String!) {
+			issue(id: $id) {` + issueDetailFields + `}
+		}
+	`,
+}
+
+// viewerData represents the data returned by viewerQuery
+type viewerData struct {
+	Viewer *User `json:"viewer"`
+}
+
+// viewerQuery fetches the user the API key belongs to
+var viewerQuery = operation[viewerData]{
+	name: "Viewer",
+	document: `
+		query Viewer {
+			viewer {
+				id
+				name
+				displayName
+				email
+			}
+		}
+	`,
+}
+
+// workflowStatesData represents the data returned by workflowStatesQuery
+type workflowStatesData struct {
+	WorkflowStates *struct {
+		Nodes []State `json:"nodes"`
+	} `json:"workflowStates"`
+}
+
+// workflowStatesQuery fetches the workflow states of a team
+var workflowStatesQuery = operation[workflowStatesData]{
+	name: "WorkflowStates",
+	document: `
+		query WorkflowStates($teamId: ID!) {
+			workflowStates(first: 100, filter: { team: { id: { eq: $teamId } } }) {
+				nodes {
+					id
+					name
+					type
+					position
+				}
+			}
+		}
+	`,
+}
+
+// issueUpdateData represents the data returned by issueUpdateMutation
+type issueUpdateData struct {
+	IssueUpdate *struct {
+		Success bool   `json:"success"`
+		Issue   *Issue `json:"issue"`
+	} `json:"issueUpdate"`
+}
+
+// issueUpdateMutation updates an issue with the given input
+var issueUpdateMutation = operation[issueUpdateData]{
+	name: "IssueUpdate",
+	document: `
+		mutation IssueUpdate($id: String!, $input: IssueUpdateInput!) {
+			issueUpdate(id: $id, input: $input) {
+				success
+				issue {` + issueFields + `}
+			}
+		}
+	`,
+	mutation: true,
+}
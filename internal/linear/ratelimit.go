@@ -0,0 +1,112 @@
+package linear
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Linear rate limit response headers
+const (
+	headerRequestsLimit       = "X-RateLimit-Requests-Limit"
+	headerRequestsRemaining   = "X-RateLimit-Requests-Remaining"
+	headerRequestsReset       = "X-RateLimit-Requests-Reset"
+	headerComplexityLimit     = "X-RateLimit-Complexity-Limit"
+	headerComplexityRemaining = "X-RateLimit-Complexity-Remaining"
+	headerComplexityReset     = "X-RateLimit-Complexity-Reset"
+	headerComplexity          = "X-Complexity"
+	headerRetryAfter          = "Retry-After"
+)
+
+// RateLimit is a snapshot of the request and complexity budget reported by
+// Linear in the headers of the most recent response. Zero values mean the
+// header was not present.
+type RateLimit struct {
+	RequestsLimit       int
+	RequestsRemaining   int
+	RequestsReset       time.Time
+	ComplexityLimit     int
+	ComplexityRemaining int
+	ComplexityReset     time.Time
+	// LastComplexity is the complexity of the most recent query
+	LastComplexity int
+}
+
+// RateLimit returns the budget reported by the most recent response
+func (c *Client) RateLimit() RateLimit {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rateLimit
+}
+
+// updateRateLimit records the budget reported in the response headers
+func (c *Client) updateRateLimit(header http.Header) {
+	rl, ok := parseRateLimit(header)
+	if !ok {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rateLimit = rl
+}
+
+// parseRateLimit reads the rate limit headers, reporting whether any were set
+func parseRateLimit(header http.Header) (RateLimit, bool) {
+	if header.Get(headerRequestsRemaining) == "" && header.Get(headerComplexityRemaining) == "" {
+		return RateLimit{}, false
+	}
+	return RateLimit{
+		RequestsLimit:       headerInt(header, headerRequestsLimit),
+		RequestsRemaining:   headerInt(header, headerRequestsRemaining),
+		RequestsReset:       headerMillis(header, headerRequestsReset),
+		ComplexityLimit:     headerInt(header, headerComplexityLimit),
+		ComplexityRemaining: headerInt(header, headerComplexityRemaining),
+		ComplexityReset:     headerMillis(header, headerComplexityReset),
+		LastComplexity:      headerInt(header, headerComplexity),
+	}, true
+}
+
+// retryAfter returns how long the server asked us to wait before retrying.
+// It honors Retry-After (seconds or HTTP date) and falls back to the reset
+// time of an exhausted rate limit budget.
+func retryAfter(header http.Header, now time.Time) (time.Duration, bool) {
+	if value := header.Get(headerRetryAfter); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(value); err == nil {
+			return max(date.Sub(now), 0), true
+		}
+	}
+
+	rl, ok := parseRateLimit(header)
+	if !ok {
+		return 0, false
+	}
+	var reset time.Time
+	if header.Get(headerRequestsRemaining) != "" && rl.RequestsRemaining <= 0 {
+		reset = rl.RequestsReset
+	}
+	if header.Get(headerComplexityRemaining) != "" && rl.ComplexityRemaining <= 0 && rl.ComplexityReset.After(reset) {
+		reset = rl.ComplexityReset
+	}
+	if reset.IsZero() {
+		return 0, false
+	}
+	return max(reset.Sub(now), 0), true
+}
+
+// headerInt parses an integer header, returning 0 if absent or invalid
+func headerInt(header http.Header, key string) int {
+	n, _ := strconv.Atoi(header.Get(key))
+	return n
+}
+
+// headerMillis parses a header holding a UTC epoch timestamp in milliseconds
+func headerMillis(header http.Header, key string) time.Time {
+	ms, err := strconv.ParseInt(header.Get(key), 10, 64)
+	if err != nil || ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
@@ -0,0 +1,34 @@
+package linear
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// identifierPattern matches a complete Linear issue identifier, e.g. DEV-123
+var identifierPattern = regexp.MustCompile(`^(?i)[a-z][a-z0-9]*-[0-9]+$`)
+
+// ParseIssueReference extracts the issue identifier from an identifier
+// ("DEV-123", "dev-123") or a Linear issue URL
+// ("https://linear.app/acme/issue/DEV-123/fix-login"). The identifier is
+// returned in uppercase.
+func ParseIssueReference(ref string) (string, error) {
+	ref = strings.TrimSpace(ref)
+	if identifierPattern.MatchString(ref) {
+		return strings.ToUpper(ref), nil
+	}
+
+	u, err := url.Parse(ref)
+	if err == nil && u.Host != "" {
+		segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+		for i, segment := range segments {
+			if segment == "issue" && i+1 < len(segments) && identifierPattern.MatchString(segments[i+1]) {
+				return strings.ToUpper(segments[i+1]), nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("%q is not a Linear issue identifier or URL", ref)
+}
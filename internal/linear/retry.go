@@ -0,0 +1,89 @@
+package linear
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryPolicy controls how failed requests are retried. Only idempotent
+// queries are retried, and only for rate limiting, server and network errors.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values below 2 disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles each attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. If the server asks to wait longer than
+	// MaxDelay the error is returned instead of retrying.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used by new clients
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   500 * time.Millisecond,
+		MaxDelay:    30 * time.Second,
+	}
+}
+
+// SetRetryPolicy replaces the retry policy of the client
+func (c *Client) SetRetryPolicy(policy RetryPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.retry = policy
+}
+
+// backoff returns the jittered exponential delay before the given retry
+// (starting at 1), in the range [d/2, d] where d = BaseDelay * 2^(retry-1)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	delay := p.BaseDelay << (retry - 1)
+	if delay <= 0 || (p.MaxDelay > 0 && delay > p.MaxDelay) {
+		delay = p.MaxDelay
+	}
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// executeWithRetry executes a request, retrying transient failures of
+// idempotent requests according to the client's retry policy. Waiting
+// between attempts stops as soon as ctx is canceled.
+func (c *Client) executeWithRetry(ctx context.Context, req graphQLRequest, idempotent bool) (json.RawMessage, error) {
+	c.mu.Lock()
+	policy := c.retry
+	c.mu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		data, err := c.executeQuery(ctx, req)
+		if err == nil || !idempotent || attempt >= policy.MaxAttempts || ctx.Err() != nil {
+			return data, err
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !apiErr.Temporary() {
+			return data, err
+		}
+
+		delay := policy.backoff(attempt)
+		if apiErr.RetryAfter > 0 {
+			if policy.MaxDelay > 0 && apiErr.RetryAfter > policy.MaxDelay {
+				return data, err
+			}
+			delay = apiErr.RetryAfter
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
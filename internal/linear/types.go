@@ -1,15 +1,127 @@
 package linear
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // State represents the state of a Linear issue
 type State struct {
+	ID       string  `json:"id,omitempty"`
+	Name     string  `json:"name"`
+	Type     string  `json:"type"`
+	Position float64 `json:"position,omitempty"`
+}
+
+// Workflow state types, in the order issues usually move through them
+const (
+	StateTypeTriage    = "triage"
+	StateTypeBacklog   = "backlog"
+	StateTypeUnstarted = "unstarted"
+	StateTypeStarted   = "started"
+	StateTypeCompleted = "completed"
+	StateTypeCanceled  = "canceled"
+)
+
+// Priority represents the priority of a Linear issue
+type Priority int
+
+// Linear issue priorities
+const (
+	PriorityNone Priority = iota
+	PriorityUrgent
+	PriorityHigh
+	PriorityMedium
+	PriorityLow
+)
+
+// String returns the name Linear uses for the priority
+func (p Priority) String() string {
+	switch p {
+	case PriorityUrgent:
+		return "Urgent"
+	case PriorityHigh:
+		return "High"
+	case PriorityMedium:
+		return "Medium"
+	case PriorityLow:
+		return "Low"
+	}
+	return "No priority"
+}
+
+// Team represents the team an issue belongs to
+type Team struct {
+	ID   string `json:"id"`
+	Key  string `json:"key"`
+	Name string `json:"name"`
+}
+
+// Label represents an issue label
+type Label struct {
+	ID   string `json:"id"`
 	Name string `json:"name"`
-	Type string `json:"type"`
 }
 
-// Issue represents a Linear issue
+// Project represents the project an issue belongs to
+type Project struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+// Cycle represents the cycle an issue is scheduled in
+type Cycle struct {
+	ID     string `json:"id"`
+	Number int    `json:"number"`
+	Name   string `json:"name"`
+}
+
+// User represents a Linear user
+type User struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Email       string `json:"email"`
+}
+
+// Issue represents a Linear issue.
+// Description, CreatedAt, Project, Cycle and Assignee are only populated by
+// GetIssue; list queries fetch a lighter selection.
 type Issue struct {
-	ID         string `json:"id"`
-	Identifier string `json:"identifier"`
-	Title      string `json:"title"`
-	State      State  `json:"state"`
+	ID          string    `json:"id"`
+	Identifier  string    `json:"identifier"`
+	Title       string    `json:"title"`
+	State       State     `json:"state"`
+	Priority    Priority  `json:"priority"`
+	Estimate    *float64  `json:"estimate"`
+	URL         string    `json:"url"`
+	BranchName  string    `json:"branchName"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	Team        Team      `json:"team"`
+	Labels      []Label   `json:"labels"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"createdAt"`
+	Project     *Project  `json:"project"`
+	Cycle       *Cycle    `json:"cycle"`
+	Assignee    *User     `json:"assignee"`
+}
+
+// UnmarshalJSON flattens the labels connection returned by the API
+func (i *Issue) UnmarshalJSON(data []byte) error {
+	type issueAlias Issue
+	var raw struct {
+		issueAlias
+		Labels *struct {
+			Nodes []Label `json:"nodes"`
+		} `json:"labels"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*i = Issue(raw.issueAlias)
+	i.Labels = nil
+	if raw.Labels != nil {
+		i.Labels = raw.Labels.Nodes
+	}
+	return nil
 }
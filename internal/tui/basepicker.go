@@ -0,0 +1,68 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// BaseItem is a branch a new branch can be created from
+type BaseItem struct {
+	Name string
+	// Note says why the branch is offered, e.g. "default branch"
+	Note string
+}
+
+// FilterValue implements list.Item interface for fuzzy search
+func (i BaseItem) FilterValue() string {
+	return i.Name
+}
+
+// NewBaseItems returns the candidate bases of a new branch: the default
+// branch, then the current branch, then every other local branch
+func NewBaseItems(defaultBranch, current string, branches []string) []list.Item {
+	items := []list.Item{BaseItem{Name: defaultBranch, Note: "default branch"}}
+	if current != defaultBranch {
+		items = append(items, BaseItem{Name: current, Note: "current branch"})
+	}
+	for _, name := range branches {
+		if name != defaultBranch && name != current {
+			items = append(items, BaseItem{Name: name})
+		}
+	}
+	return items
+}
+
+// BaseDelegate is a custom delegate for rendering base branch items
+type BaseDelegate struct{}
+
+// Height implements list.ItemDelegate
+func (d BaseDelegate) Height() int { return 1 }
+
+// Spacing implements list.ItemDelegate
+func (d BaseDelegate) Spacing() int { return 0 }
+
+// Update implements list.ItemDelegate
+func (d BaseDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+
+// Render implements list.ItemDelegate
+func (d BaseDelegate) Render(w io.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(BaseItem)
+	if !ok {
+		return
+	}
+
+	str := i.Name
+	if i.Note != "" {
+		str += helpStyle.Render(fmt.Sprintf("  (%s)", i.Note))
+	}
+
+	fn := itemStyle.Render
+	if index == m.Index() {
+		fn = selectedItemStyle.Render
+	}
+
+	fmt.Fprint(w, fn(str))
+}
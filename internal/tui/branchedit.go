@@ -1,61 +1,81 @@
 package tui
 
 import (
-	"regexp"
-	"strings"
-	"unicode"
+	"fmt"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/metalgrid/git-linear/internal/branch"
+	"github.com/metalgrid/git-linear/internal/linear"
 )
 
 var prefixStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 
 // sanitizeSuffix sanitizes a branch name suffix (without prefix combination or length truncation)
 func sanitizeSuffix(s string) string {
-	s = strings.ReplaceAll(s, " ", "-")
-	s = removeNonASCII(s)
-	// Consecutive dots are invalid in Git refs
-	s = regexp.MustCompile(`\.\.+`).ReplaceAllString(s, "-")
-	s = regexp.MustCompile(`[^a-zA-Z0-9-_./]`).ReplaceAllString(s, "")
-	s = regexp.MustCompile(`-+`).ReplaceAllString(s, "-")
-	s = strings.Trim(s, "-")
-	return s
-}
-
-// removeNonASCII removes all non-ASCII characters from a string
-func removeNonASCII(s string) string {
-	return strings.Map(func(r rune) rune {
-		if r > unicode.MaxASCII {
-			return -1
-		}
-		return r
-	}, s)
+	return branch.Slugify(s)
 }
 
 // BranchEditor wraps textinput for editing branch names with locked prefix
 type BranchEditor struct {
-	prefix    string
-	textInput textinput.Model
+	// suggestion holds the locked prefix and how the name is built; its
+	// suffix is the value of textInput
+	suggestion branch.Suggestion
+	textInput  textinput.Model
+	// invalidPrefix is the suggested prefix when git rejects it. The whole
+	// name is editable then, as the user could not fix it otherwise.
+	invalidPrefix string
 }
 
-// NewBranchEditor creates a new branch editor with locked prefix
+// NewBranchEditor creates a new branch editor with locked prefix, joined to
+// the suffix with a hyphen
 func NewBranchEditor(prefix, defaultSuffix string) BranchEditor {
+	return NewBranchEditorFor(branch.Suggestion{
+		Strategy:  branch.StrategySanitize,
+		Prefix:    prefix,
+		Separator: "-",
+		Suffix:    defaultSuffix,
+		MaxLength: branch.DefaultMaxLength,
+	})
+}
+
+// NewBranchEditorFor creates a branch editor for a suggested branch name,
+// locking its prefix and editing its suffix. If the name is invalid, which
+// a slugified suffix cannot cause, the prefix is unlocked.
+func NewBranchEditorFor(s branch.Suggestion) BranchEditor {
+	invalidPrefix := ""
+	if s.Prefix != "" && branch.Validate(s.Name()) != nil {
+		invalidPrefix = s.Prefix
+		s = branch.Suggestion{
+			Strategy:      s.Strategy,
+			Suffix:        s.Name(),
+			MaxLength:     s.MaxLength,
+			DropStopWords: s.DropStopWords,
+		}
+	}
+
 	ti := textinput.New()
 	ti.Placeholder = "branch-description"
 	ti.Focus()
 	ti.CharLimit = 50
 	ti.Width = 50
-	ti.SetValue(sanitizeSuffix(defaultSuffix))
+	ti.SetValue(sanitizeSuffix(s.Suffix))
 
 	return BranchEditor{
-		prefix:    prefix,
-		textInput: ti,
+		suggestion:    s,
+		textInput:     ti,
+		invalidPrefix: invalidPrefix,
 	}
 }
 
+// NewLinearBranchEditor creates a branch editor for a branch name suggested
+// by Linear. Everything up to the issue identifier is locked and the name is
+// used as is, without lowercasing or truncation.
+func NewLinearBranchEditor(name, identifier string) BranchEditor {
+	return NewBranchEditorFor(branch.Suggest(branch.StrategyLinear, nil, linear.Issue{Identifier: identifier}, "", name))
+}
+
 // Init implements tea.Model
 func (e BranchEditor) Init() tea.Cmd {
 	return textinput.Blink
@@ -75,17 +95,32 @@ func (e BranchEditor) Update(msg tea.Msg) (BranchEditor, tea.Cmd) {
 	return e, cmd
 }
 
-// View implements tea.Model
+// View implements tea.Model. The reasons an invalid name is refused are
+// shown below it.
 func (e BranchEditor) View() string {
-	return prefixStyle.Render(e.prefix+"-") + e.textInput.View()
+	view := e.textInput.View()
+	if e.suggestion.Prefix != "" {
+		view = prefixStyle.Render(e.suggestion.Prefix+e.suggestion.Separator) + view
+	}
+	if e.invalidPrefix != "" {
+		view += "\n" + helpStyle.Render(fmt.Sprintf("%q is not a valid start of a branch name, so the whole name is editable", e.invalidPrefix))
+	}
+	if err := e.Err(); err != nil {
+		view += "\n" + errorStyle.Render(err.Error())
+	}
+	return view
+}
+
+// Err returns why the branch name is invalid, or nil if it is valid
+func (e BranchEditor) Err() error {
+	return branch.Validate(e.Value())
 }
 
 // Value returns the full sanitized branch name
 func (e BranchEditor) Value() string {
-	suffix := e.textInput.Value()
-	// Use branch.Sanitize to get the full sanitized name
-	// The prefix is already lowercase from Linear ID, suffix needs sanitization
-	return branch.Sanitize(e.prefix, suffix)
+	s := e.suggestion
+	s.Suffix = e.textInput.Value()
+	return s.Name()
 }
 
 // Focus sets focus on the text input
@@ -1,6 +1,8 @@
 package tui_test
 
 import (
+	"github.com/metalgrid/git-linear/internal/branch"
+	"github.com/metalgrid/git-linear/internal/linear"
 	"github.com/metalgrid/git-linear/internal/tui"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -25,12 +27,60 @@ var _ = Describe("BranchEditor", func() {
 			Expect(editor.Value()).To(Equal("dev-456-Hello-World"))
 		})
 
+		It("transliterates non-ASCII letters in suffix", func() {
+			editor := tui.NewBranchEditor("dev-321", "Grüße Привет")
+			Expect(editor.Value()).To(Equal("dev-321-Gruesse-Privet"))
+		})
+
 		It("handles empty suffix", func() {
 			editor := tui.NewBranchEditor("dev-789", "")
 			Expect(editor.Value()).To(Equal("dev-789"))
 		})
 	})
 
+	Describe("NewLinearBranchEditor", func() {
+		It("keeps Linear's branch name verbatim", func() {
+			editor := tui.NewLinearBranchEditor("jane/dev-123-fix-the-login-flow-on-mobile", "DEV-123")
+			Expect(editor.Value()).To(Equal("jane/dev-123-fix-the-login-flow-on-mobile"))
+		})
+
+		It("locks everything up to the identifier", func() {
+			editor := tui.NewLinearBranchEditor("jane/dev-123-fix-login", "DEV-123")
+			Expect(editor.View()).To(ContainSubstring("jane/dev-123-"))
+		})
+	})
+
+	Describe("NewBranchEditorFor", func() {
+		It("locks the prefix built by the branch template", func() {
+			t, err := branch.ParseTemplate("{{.Username}}/{{.Identifier}}-{{.Slug}}", 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			editor := tui.NewBranchEditorFor(t.Suggest(linear.Issue{Identifier: "DEV-123", Title: "Fix login"}, "jane"))
+			Expect(editor.View()).To(ContainSubstring("jane/dev-123-"))
+			Expect(editor.Value()).To(Equal("jane/dev-123-Fix-login"))
+		})
+	})
+
+	Describe("Err", func() {
+		It("accepts valid names", func() {
+			editor := tui.NewBranchEditor("dev-123", "fix login")
+			Expect(editor.Err()).NotTo(HaveOccurred())
+		})
+
+		It("refuses names git rejects and shows why", func() {
+			editor := tui.NewBranchEditor("", "")
+			Expect(editor.Err()).To(HaveOccurred())
+			Expect(editor.View()).To(ContainSubstring("it is empty"))
+		})
+
+		It("unlocks a prefix git rejects and says so", func() {
+			editor := tui.NewLinearBranchEditor("jane/.config.lock/dev-123-fix", "DEV-123")
+			Expect(editor.Err()).NotTo(HaveOccurred())
+			Expect(editor.Value()).To(Equal("jane/config/dev-123-fix"))
+			Expect(editor.View()).To(ContainSubstring("the whole name is editable"))
+		})
+	})
+
 	Describe("View", func() {
 		It("renders prefix and editable suffix", func() {
 			editor := tui.NewBranchEditor("dev-123", "test")
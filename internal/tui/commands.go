@@ -1,37 +1,167 @@
 package tui
 
 import (
+	"context"
+	"fmt"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/metalgrid/git-linear/internal/git"
+	"github.com/metalgrid/git-linear/internal/linear"
 )
 
-// loadIssuesCmd fetches issues from Linear
+// loadIssuesCmd fetches the next page of issues from Linear
 func (m Model) loadIssuesCmd() tea.Msg {
-	issues, err := m.linearClient.GetAssignedIssues()
-	return issuesLoadedMsg{issues: issues, err: err}
+	m.issueIter.Next()
+	return issuesLoadedMsg{
+		issues: m.issueIter.Issues(),
+		more:   m.issueIter.More(),
+		err:    m.issueIter.Err(),
+	}
 }
 
-// createBranchCmd creates a new git branch
-func (m Model) createBranchCmd() tea.Msg {
-	// Get default branch
-	defaultBranch, err := git.GetDefaultBranch()
-	if err != nil {
-		return branchCreatedMsg{err: err}
+// fetchBaseCmd fetches the default branch of the remote to create the new
+// branch from
+func (m Model) fetchBaseCmd(ctx context.Context, stash bool) tea.Cmd {
+	repo, remote := m.repo, m.remote
+	return func() tea.Msg {
+		base, err := repo.FetchBase(ctx, remote)
+		return baseFetchedMsg{base: base, stash: stash, err: err}
 	}
+}
 
-	// Create branch from default
-	err = git.CreateBranch(m.branchName, defaultBranch)
-	if err != nil {
-		return branchCreatedMsg{err: err}
-	}
+// createBranchCmd creates a new git branch from base, or from the chosen
+// base or local default branch if base is empty, tracking the same branch
+// on the remote. A branch created from a chosen base is stacked on it.
+func (m Model) createBranchCmd(stash bool, base string) tea.Cmd {
+	ctx := m.mutationCtx()
+	name := m.branchName
+	worktree, layout := m.worktree, m.worktreeLayout
+	repo, remote := m.repo, m.remote
+	parent := m.base
+	return m.withStash(ctx, stash, func() branchCreatedMsg {
+		if base == "" {
+			base = parent
+		}
+		if base == "" {
+			defaultBranch, err := repo.GetDefaultBranch(ctx)
+			if err != nil {
+				return branchCreatedMsg{err: err}
+			}
+			base = defaultBranch
+		}
+
+		var msg branchCreatedMsg
+		if worktree {
+			path, _, err := repo.EnsureWorktree(ctx, layout, name, base)
+			if err != nil {
+				return branchCreatedMsg{err: err}
+			}
+			msg.worktree = path
+		} else if err := repo.CreateBranch(ctx, name, base); err != nil {
+			return branchCreatedMsg{err: err}
+		}
 
-	// Switch to new branch
-	err = git.SwitchBranch(m.branchName)
-	return branchCreatedMsg{err: err}
+		if parent != "" {
+			if err := repo.SetParent(ctx, name, parent); err != nil {
+				msg.warning = fmt.Sprintf("⚠ Could not record %s as the parent of %s: %v", parent, name, err)
+			}
+		}
+		if repo.RemoteExists(ctx, remote) {
+			if err := repo.SetUpstream(ctx, name, remote); err != nil {
+				msg.warning = joinLines(msg.warning, fmt.Sprintf("⚠ Could not set the upstream of %s: %v", name, err))
+			}
+		}
+
+		if !worktree {
+			msg.err = repo.SwitchBranch(ctx, name)
+		}
+		return msg
+	})
 }
 
-// switchBranchCmd switches to an existing branch
-func (m Model) switchBranchCmd() tea.Msg {
-	err := git.SwitchBranch(m.existingBranch)
-	return branchCreatedMsg{err: err}
+// switchBranchCmd switches to an existing branch. A remote-only branch is
+// first checked out as a local branch tracking it.
+func (m Model) switchBranchCmd(stash bool) tea.Cmd {
+	ctx := m.mutationCtx()
+	repo, ref := m.repo, m.existingRef
+	name := ref.Branch
+	worktree, layout := m.worktree, m.worktreeLayout
+	return m.withStash(ctx, stash, func() branchCreatedMsg {
+		var msg branchCreatedMsg
+		if !ref.Local() {
+			if err := repo.CreateTrackingBranch(ctx, ref); err != nil {
+				return branchCreatedMsg{err: err}
+			}
+			msg.tracking = ref.String()
+		}
+
+		if worktree {
+			path, _, err := repo.EnsureWorktree(ctx, layout, name, "")
+			msg.worktree, msg.err = path, err
+			return msg
+		}
+
+		// Git refuses to check out a branch used by another worktree
+		path, found, err := repo.OtherWorktree(ctx, name)
+		if err != nil {
+			msg.err = err
+			return msg
+		}
+		if found {
+			msg.worktree = path
+			return msg
+		}
+
+		msg.err = repo.SwitchBranch(ctx, name)
+		return msg
+	})
+}
+
+// mutationCtx returns the context for git commands that change the
+// repository. Killing them half-way could leave a branch created but not
+// checked out, or a stale index.lock, so they are not cancelled.
+func (m Model) mutationCtx() context.Context {
+	return context.WithoutCancel(m.ctx)
+}
+
+// withStash wraps a branch operation so that, if stash is set, changes to
+// tracked files are stashed before it and re-applied on the new branch
+// afterwards. The stash is labelled with the issue identifier so that it
+// can be found again if re-applying fails.
+func (m Model) withStash(ctx context.Context, stash bool, op func() branchCreatedMsg) tea.Cmd {
+	repo, identifier := m.repo, m.selectedIssue.Identifier
+	return func() tea.Msg {
+		if !stash {
+			return op()
+		}
+
+		current, err := repo.GetCurrentBranch(ctx)
+		if err != nil {
+			return branchCreatedMsg{err: err}
+		}
+		label := git.StashMessage(identifier, current)
+		commit, err := repo.StashPush(ctx, label)
+		if err != nil {
+			return branchCreatedMsg{err: fmt.Errorf("failed to stash changes: %w", err)}
+		}
+
+		msg := op()
+		if commit == "" {
+			return msg
+		}
+		// On failure we are still on the original branch, so restore the
+		// changes there; on success they move to the new branch
+		if err := repo.StashPop(ctx, commit); err != nil {
+			msg.warning = joinLines(msg.warning, fmt.Sprintf("⚠ Could not re-apply your changes, they are kept in the stash %q", label))
+		}
+		return msg
+	}
+}
+
+// startIssueCmd moves an issue to its team's first started workflow state
+func (m Model) startIssueCmd(issue linear.Issue) tea.Cmd {
+	return func() tea.Msg {
+		state, err := m.linearClient.StartIssue(m.ctx, issue)
+		return issueStartedMsg{state: state, err: err}
+	}
 }
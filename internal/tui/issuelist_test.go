@@ -1,6 +1,7 @@
 package tui_test
 
 import (
+	"github.com/charmbracelet/bubbles/list"
 	"github.com/metalgrid/git-linear/internal/linear"
 	"github.com/metalgrid/git-linear/internal/tui"
 	. "github.com/onsi/ginkgo/v2"
@@ -48,3 +49,19 @@ var _ = Describe("IssueItem", func() {
 		})
 	})
 })
+
+var _ = Describe("NewBaseItems", func() {
+	It("offers the default and current branch first", func() {
+		items := tui.NewBaseItems("main", "dev-1-schema", []string{"dev-1-schema", "dev-2-api", "main"})
+		Expect(items).To(Equal([]list.Item{
+			tui.BaseItem{Name: "main", Note: "default branch"},
+			tui.BaseItem{Name: "dev-1-schema", Note: "current branch"},
+			tui.BaseItem{Name: "dev-2-api"},
+		}))
+	})
+
+	It("does not repeat the default branch when it is checked out", func() {
+		items := tui.NewBaseItems("main", "main", []string{"main"})
+		Expect(items).To(Equal([]list.Item{tui.BaseItem{Name: "main", Note: "default branch"}}))
+	})
+})
@@ -1,7 +1,12 @@
 package tui
 
 import (
+	"context"
+
 	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/metalgrid/git-linear/internal/branch"
+	"github.com/metalgrid/git-linear/internal/git"
 	"github.com/metalgrid/git-linear/internal/linear"
 )
 
@@ -14,27 +19,175 @@ type Model struct {
 	branchName     string
 	errorMsg       string
 	resultMsg      string
+	warningMsg     string
+	pendingMsg     string
 	width          int
 	height         int
 	linearClient   *linear.Client
-	existingBranch string
+	repo           git.Repository
+	issueIter      *linear.IssueIterator
+	issueOpts      linear.IssueQueryOptions
+	loadingMore    bool
+	existingRef    git.Ref
+	branchStrategy branch.Strategy
+	branchTemplate *branch.Template
+	username       string
+	startIssue     bool
+	worktree       bool
+	worktreeLayout string
+	worktreePath   string
+	remote         string
+	fetchBase      bool
+	status         git.Status
+	dirtyReturn    State
+
+	// refs is the snapshot of branches used to mark issues in the list,
+	// taken when the first page is loaded
+	refs *git.RefSnapshot
+	// matches are the existing branches of the selected issue
+	matches     []git.Ref
+	matchCursor int
+
+	// base is the branch the new branch is stacked on; empty for the
+	// default branch
+	base          string
+	defaultBranch string
+	basePicker    list.Model
+
+	// ctx is canceled when the TUI quits, aborting all in-flight work
+	ctx    context.Context
+	cancel context.CancelFunc
+	// cancelOp aborts the in-flight fetch of the base, if any
+	cancelOp context.CancelFunc
+	// mutating is set while git changes branches, the index or the stash.
+	// That cannot be cancelled safely, so esc is ignored and quitting
+	// waits until it is done.
+	mutating bool
+	quitting bool
+}
+
+// Options configures the behaviour of the TUI
+type Options struct {
+	// Issues controls the page size and limit used when fetching issues
+	Issues linear.IssueQueryOptions
+	// BranchStrategy selects how branch names are suggested
+	BranchStrategy branch.Strategy
+	// BranchTemplate builds names with the sanitize strategy, by default
+	// branch.DefaultTemplate
+	BranchTemplate *branch.Template
+	// Username is the viewer's Linear username used by BranchTemplate
+	Username string
+	// StartIssue moves the selected issue to started once its branch is checked out
+	StartIssue bool
+	// Worktree checks branches out in their own worktree laid out by WorktreeLayout
+	Worktree       bool
+	WorktreeLayout string
+	// Remote is the remote new branches track
+	Remote string
+	// FetchBase creates new branches from the freshly fetched default
+	// branch of Remote
+	FetchBase bool
+	// Repository is the repository branches are created in, by default
+	// the one of the current directory operated on with the git binary
+	Repository git.Repository
 }
 
-// NewModel creates a new TUI model
-func NewModel(client *linear.Client) Model {
+// NewModel creates a new TUI model. Work started by the model is canceled
+// when ctx is done or the user quits.
+func NewModel(ctx context.Context, client *linear.Client, opts Options) Model {
+	ctx, cancel := context.WithCancel(ctx)
+	repo := opts.Repository
+	if repo == nil {
+		repo = git.NewExecRepository()
+	}
 	return Model{
-		state:        StateLoading,
-		linearClient: client,
+		state:          StateLoading,
+		linearClient:   client,
+		repo:           repo,
+		issueIter:      client.AssignedIssues(ctx, opts.Issues),
+		issueOpts:      opts.Issues,
+		ctx:            ctx,
+		cancel:         cancel,
+		branchStrategy: opts.BranchStrategy,
+		branchTemplate: opts.BranchTemplate,
+		username:       opts.Username,
+		startIssue:     opts.StartIssue,
+		worktree:       opts.Worktree,
+		worktreeLayout: opts.WorktreeLayout,
+		remote:         opts.Remote,
+		fetchBase:      opts.FetchBase,
+	}
+}
+
+// suggestedBranch returns the branch name suggested for an issue by the
+// configured strategy. Linear's name is only used if it is a valid branch name.
+func (m Model) suggestedBranch(issue linear.Issue) branch.Suggestion {
+	linearName := ""
+	if m.branchStrategy == branch.StrategyLinear && issue.BranchName != "" &&
+		branch.Validate(issue.BranchName) == nil {
+		linearName = issue.BranchName
+	}
+	return branch.Suggest(m.branchStrategy, m.branchTemplate, issue, m.username, linearName)
+}
+
+// startOp cancels the in-flight branch operation and returns the context
+// for a new one
+func (m *Model) startOp() context.Context {
+	m.stopOp()
+	ctx, cancel := context.WithCancel(m.ctx)
+	m.cancelOp = cancel
+	return ctx
+}
+
+// stopOp cancels the in-flight branch operation, if any
+func (m *Model) stopOp() {
+	if m.cancelOp != nil {
+		m.cancelOp()
+		m.cancelOp = nil
 	}
 }
 
-// issuesLoadedMsg is sent when issues are loaded
+// quit cancels all in-flight work and exits the program, once git is done
+// changing the repository
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	m.stopOp()
+	m.cancel()
+	if m.mutating {
+		m.quitting = true
+		return m, nil
+	}
+	return m, tea.Quit
+}
+
+// issuesLoadedMsg is sent when a page of issues is loaded
 type issuesLoadedMsg struct {
 	issues []linear.Issue
+	more   bool
 	err    error
 }
 
 // branchCreatedMsg is sent when a branch is created
 type branchCreatedMsg struct {
-	err error
+	// worktree is set when the branch is checked out in another worktree
+	worktree string
+	// tracking is the remote-tracking branch a new local branch was
+	// created from
+	tracking string
+	// warning reports a problem that did not prevent the switch
+	warning string
+	err     error
+}
+
+// baseFetchedMsg is sent when the base of a new branch has been fetched
+type baseFetchedMsg struct {
+	// base is the remote-tracking branch to create the branch from
+	base  string
+	stash bool
+	err   error
+}
+
+// issueStartedMsg is sent when the selected issue has been moved to started
+type issueStartedMsg struct {
+	state linear.State
+	err   error
 }
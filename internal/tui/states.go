@@ -8,7 +8,9 @@ const (
 	StateIssueList
 	StateBranchEdit
 	StateConfirm
+	StateBasePicker
 	StateExistingBranch
+	StateDirtyTree
 	StateResult
 	StateError
 )
@@ -1,12 +1,14 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
 
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/metalgrid/git-linear/internal/branch"
 	"github.com/metalgrid/git-linear/internal/git"
+	"github.com/metalgrid/git-linear/internal/linear"
 )
 
 // Init implements tea.Model
@@ -18,17 +20,30 @@ func (m Model) Init() tea.Cmd {
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.state == StateDirtyTree && msg.String() != "ctrl+c" {
+			return m.handleDirtyTreeKey(msg)
+		}
+		if m.state == StateBasePicker && msg.String() != "ctrl+c" {
+			return m.handleBasePickerKey(msg)
+		}
 		switch msg.String() {
-		case "ctrl+c", "q":
+		case "ctrl+c":
+			return m.quit()
+		case "q":
 			if m.state == StateIssueList || m.state == StateError || m.state == StateResult {
-				return m, tea.Quit
+				return m.quit()
 			}
 		case "esc":
 			if m.state == StateBranchEdit {
 				m.state = StateIssueList
 				return m, nil
 			}
+			if m.mutating {
+				break
+			}
 			if m.state == StateConfirm {
+				m.stopOp()
+				m.pendingMsg = ""
 				m.state = StateBranchEdit
 				return m, m.branchEditor.Focus()
 			}
@@ -36,6 +51,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = StateIssueList
 				return m, nil
 			}
+		case "r":
+			// Pages of the previous load would be mixed into the new list
+			if m.state == StateIssueList && !m.loadingMore && m.issueList.FilterState() != list.Filtering {
+				return m.reload()
+			}
+		case "up", "k":
+			if m.state == StateExistingBranch && m.matchCursor > 0 {
+				m.matchCursor--
+			}
+		case "down", "j":
+			if m.state == StateExistingBranch && m.matchCursor < len(m.matches)-1 {
+				m.matchCursor++
+			}
+		case "n":
+			if m.state == StateExistingBranch {
+				return m.editBranch()
+			}
+		case "b":
+			// The base cannot change while the branch is being created
+			if m.state == StateConfirm && m.cancelOp == nil {
+				return m.openBasePicker()
+			}
 		case "enter":
 			return m.handleEnter()
 		}
@@ -46,42 +83,61 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.state == StateIssueList {
 			m.issueList.SetSize(msg.Width, msg.Height-5)
 		}
+		if m.state == StateBasePicker {
+			m.basePicker.SetSize(msg.Width, msg.Height-5)
+		}
 
 	case issuesLoadedMsg:
-		if msg.err != nil {
-			m.state = StateError
-			m.errorMsg = fmt.Sprintf("Failed to load issues: %v", msg.err)
-			return m, nil
-		}
-		if len(msg.issues) == 0 {
-			m.state = StateError
-			m.errorMsg = "No assigned issues found"
+		return m.handleIssuesLoaded(msg)
+
+	case baseFetchedMsg:
+		// The user navigated away while fetching, maybe too late to
+		// interrupt it
+		if m.cancelOp == nil || errors.Is(msg.err, context.Canceled) {
 			return m, nil
 		}
-
-		// Convert issues to list items
-		items := make([]list.Item, len(msg.issues))
-		for i, issue := range msg.issues {
-			// Check if branch exists for this issue
-			branchName := branch.Sanitize(issue.Identifier, issue.Title)
-			branchExists := git.BranchExists(branchName)
-			items[i] = IssueItem{Issue: issue, BranchExists: branchExists}
+		m.stopOp()
+		m.pendingMsg = ""
+		if msg.err != nil {
+			m.warningMsg = fmt.Sprintf("⚠ Could not fetch %s, branching from the local default branch: %v", m.remote, msg.err)
 		}
-
-		m.issueList = list.New(items, IssueDelegate{}, m.width, m.height-5)
-		m.issueList.Title = "Select an Issue"
-		m.state = StateIssueList
-		return m, nil
+		m.mutating = true
+		return m, m.createBranchCmd(msg.stash, msg.base)
 
 	case branchCreatedMsg:
+		m.mutating = false
+		m.warningMsg = joinLines(m.warningMsg, msg.warning)
 		if msg.err != nil {
 			m.state = StateError
-			m.errorMsg = fmt.Sprintf("Failed to create/switch branch: %v", msg.err)
+			m.errorMsg = joinLines(fmt.Sprintf("Failed to create/switch branch: %v", msg.err), gitErrorHint(msg.err), m.warningMsg)
+			if m.quitting {
+				return m, tea.Quit
+			}
 			return m, nil
 		}
 		m.state = StateResult
 		m.resultMsg = fmt.Sprintf("✓ Switched to branch: %s", m.branchName)
-		return m, tea.Quit
+		if msg.worktree != "" {
+			m.worktreePath = msg.worktree
+			m.resultMsg = fmt.Sprintf("✓ Branch %s is checked out in worktree: %s", m.branchName, msg.worktree)
+		}
+		if msg.tracking != "" {
+			m.resultMsg = fmt.Sprintf("✓ Created branch %s tracking %s\n", m.branchName, msg.tracking) + m.resultMsg
+		}
+		if m.startIssue && m.selectedIssue != nil && !m.quitting {
+			m.pendingMsg = fmt.Sprintf("Moving %s to started...", m.selectedIssue.Identifier)
+			return m, m.startIssueCmd(*m.selectedIssue)
+		}
+		return m.quit()
+
+	case issueStartedMsg:
+		m.pendingMsg = ""
+		if msg.err != nil {
+			m.warningMsg = joinLines(m.warningMsg, fmt.Sprintf("⚠ Could not update %s: %v", m.selectedIssue.Identifier, msg.err))
+		} else if msg.state.ID != m.selectedIssue.State.ID {
+			m.resultMsg += fmt.Sprintf("\n✓ Moved %s to %s", m.selectedIssue.Identifier, msg.state.Name)
+		}
+		return m.quit()
 	}
 
 	// Update active component based on state
@@ -96,6 +152,222 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// handleIssuesLoaded appends a page of issues to the list and requests the
+// next page while more are available
+func (m Model) handleIssuesLoaded(msg issuesLoadedMsg) (tea.Model, tea.Cmd) {
+	firstPage := m.state == StateLoading
+
+	if msg.err != nil {
+		m.loadingMore = false
+		if firstPage {
+			m.state = StateError
+			m.errorMsg = fmt.Sprintf("Failed to load issues: %v", msg.err)
+			if hint := linearErrorHint(msg.err); hint != "" {
+				m.errorMsg += "\n" + hint
+			}
+			return m, nil
+		}
+		return m, m.issueList.NewStatusMessage(errorStyle.Render(fmt.Sprintf("Failed to load more issues: %v", msg.err)))
+	}
+
+	// A single snapshot of the branches serves all pages of the list;
+	// without one issues are simply not marked
+	if firstPage {
+		m.refs, _ = m.repo.SnapshotRefs(m.ctx)
+	}
+
+	// Convert issues to list items
+	items := make([]list.Item, len(msg.issues))
+	for i, issue := range msg.issues {
+		items[i] = IssueItem{Issue: issue, BranchExists: m.refs.Has(issue.Identifier)}
+	}
+
+	var cmds []tea.Cmd
+	if firstPage {
+		if len(items) == 0 && !msg.more {
+			m.state = StateError
+			m.errorMsg = "No assigned issues found"
+			return m, nil
+		}
+		m.issueList = list.New(items, IssueDelegate{}, m.width, m.height-5)
+		m.issueList.Title = "Select an Issue"
+		m.state = StateIssueList
+	} else if len(items) > 0 {
+		cmds = append(cmds, m.issueList.SetItems(append(m.issueList.Items(), items...)))
+	}
+
+	m.loadingMore = msg.more
+	if msg.more {
+		cmds = append(cmds, m.loadIssuesCmd)
+	}
+
+	return m, tea.Batch(cmds...)
+}
+
+// reload fetches the issues again and refreshes the branch snapshot
+func (m Model) reload() (tea.Model, tea.Cmd) {
+	m.issueIter = m.linearClient.AssignedIssues(m.ctx, m.issueOpts)
+	m.refs = nil
+	m.state = StateLoading
+	return m, m.loadIssuesCmd
+}
+
+// linearErrorHint suggests how to resolve a Linear API error
+func linearErrorHint(err error) string {
+	switch {
+	case errors.Is(err, linear.ErrUnauthorized):
+		return "Run 'git linear auth' to store a valid API key."
+	case errors.Is(err, linear.ErrForbidden):
+		return "Check that your API key has access to this workspace."
+	case errors.Is(err, linear.ErrRateLimited):
+		return "Linear's rate limit was reached, try again in a few minutes."
+	case errors.Is(err, linear.ErrTransport), errors.Is(err, linear.ErrServer):
+		return "Check your network connection and try again."
+	}
+	return ""
+}
+
+// gitErrorHint suggests how to resolve a git error
+func gitErrorHint(err error) string {
+	switch {
+	case errors.Is(err, git.ErrWouldOverwrite):
+		return "Commit your changes, or choose to stash them when switching."
+	case errors.Is(err, git.ErrIndexLocked):
+		return "Another git process is running; if none is, remove .git/index.lock."
+	case errors.Is(err, git.ErrRefExists):
+		return "Pick the existing branch or edit the branch name."
+	case errors.Is(err, git.ErrInvalidBranchName):
+		return "Edit the branch name and try again."
+	case errors.Is(err, git.ErrUnknownRevision):
+		return "The branch to create it from does not exist. Pick another base or check linear.remote."
+	case errors.Is(err, git.ErrNotRepository):
+		return "Run git linear inside a git repository."
+	}
+	return ""
+}
+
+// handleDirtyTreeKey handles the choice of what to do with uncommitted
+// changes. Without a backend that keeps changes, aborting is the only one.
+func (m Model) handleDirtyTreeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "s", "enter":
+		if m.repo.KeepsChanges() {
+			return m.checkout(true)
+		}
+	case "c":
+		if m.repo.KeepsChanges() {
+			return m.checkout(false)
+		}
+	case "a", "esc", "q":
+		m.state = m.dirtyReturn
+	}
+	return m, nil
+}
+
+// openBasePicker lists the branches the new branch can be created from
+func (m Model) openBasePicker() (tea.Model, tea.Cmd) {
+	current, err := m.repo.GetCurrentBranch(m.ctx)
+	if err != nil {
+		current = m.defaultBranch
+	}
+	branches, err := m.repo.ListLocalBranches(m.ctx)
+	if err != nil {
+		m.state = StateError
+		m.errorMsg = fmt.Sprintf("Failed to list branches: %v", err)
+		return m, nil
+	}
+
+	items := NewBaseItems(m.defaultBranch, current, branches)
+	m.basePicker = list.New(items, BaseDelegate{}, m.width, m.height-5)
+	m.basePicker.Title = "Select a base branch"
+	for i, item := range items {
+		if item.(BaseItem).Name == m.base {
+			m.basePicker.Select(i)
+		}
+	}
+	m.state = StateBasePicker
+	return m, nil
+}
+
+// handleBasePickerKey handles keys in the base branch picker, leaving
+// them to the list while a filter is being typed
+func (m Model) handleBasePickerKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	filtering := m.basePicker.FilterState() == list.Filtering
+	switch msg.String() {
+	case "enter":
+		if filtering {
+			break
+		}
+		if item, ok := m.basePicker.SelectedItem().(BaseItem); ok {
+			m.base = item.Name
+			if item.Name == m.defaultBranch {
+				m.base = ""
+			}
+		}
+		m.state = StateConfirm
+		return m, nil
+	case "esc":
+		if m.basePicker.FilterState() != list.Unfiltered {
+			break
+		}
+		m.state = StateConfirm
+		return m, nil
+	case "q":
+		if !filtering {
+			m.state = StateConfirm
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.basePicker, cmd = m.basePicker.Update(msg)
+	return m, cmd
+}
+
+// hasModifications refreshes the working tree status and reports whether
+// tracked files have changes. Untracked files are carried over untouched,
+// and worktree mode never touches the current working tree.
+func (m *Model) hasModifications() bool {
+	if m.worktree {
+		return false
+	}
+	status, err := m.repo.GetStatus(m.ctx)
+	if err != nil {
+		return false
+	}
+	m.status = status
+	return status.HasModifications()
+}
+
+// checkout creates or switches to the selected branch, optionally stashing
+// uncommitted changes and re-applying them on the branch
+func (m Model) checkout(stash bool) (tea.Model, tea.Cmd) {
+	if m.state == StateDirtyTree {
+		m.state = m.dirtyReturn
+	}
+	m.warningMsg = ""
+	if m.state == StateExistingBranch {
+		// Switch to existing branch
+		m.branchName = m.existingRef.Branch
+		m.mutating = true
+		return m, m.switchBranchCmd(stash)
+	}
+	if m.fetchBase && m.base == "" {
+		m.pendingMsg = fmt.Sprintf("Fetching %s...", m.remote)
+		return m, m.fetchBaseCmd(m.startOp(), stash)
+	}
+	m.mutating = true
+	return m, m.createBranchCmd(stash, "")
+}
+
+// editBranch lets the user edit the suggested name of a new branch for the
+// selected issue
+func (m Model) editBranch() (tea.Model, tea.Cmd) {
+	m.branchEditor = NewBranchEditorFor(m.suggestedBranch(*m.selectedIssue))
+	m.state = StateBranchEdit
+	return m, m.branchEditor.Focus()
+}
+
 func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 	switch m.state {
 	case StateIssueList:
@@ -105,41 +377,49 @@ func (m Model) handleEnter() (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.selectedIssue = &item.Issue
+		m.base = ""
 
-		// Generate branch name
-		branchName := branch.Sanitize(item.Issue.Identifier, item.Issue.Title)
-
-		// Check if branch exists
-		if git.BranchExists(branchName) {
-			m.existingBranch = branchName
+		// Offer the branches already created for the issue, whatever
+		// their name
+		refs, err := m.repo.SnapshotRefs(m.ctx)
+		if matches := refs.Find(item.Issue.Identifier); err == nil && len(matches) > 0 {
+			m.matches = matches
+			m.matchCursor = 0
 			m.state = StateExistingBranch
 			return m, nil
 		}
 
-		// Move to branch edit
-		m.branchEditor = NewBranchEditor(
-			branch.Sanitize(item.Issue.Identifier, ""),
-			item.Issue.Title,
-		)
-		m.state = StateBranchEdit
-		return m, m.branchEditor.Focus()
+		return m.editBranch()
 
 	case StateBranchEdit:
+		// The editor shows why the name is refused
+		if m.branchEditor.Err() != nil {
+			return m, nil
+		}
 		m.branchName = m.branchEditor.Value()
+		m.defaultBranch, _ = m.repo.GetDefaultBranch(m.ctx)
 		m.state = StateConfirm
 		m.branchEditor.Blur()
 		return m, nil
 
-	case StateConfirm:
-		return m, m.createBranchCmd
-
-	case StateExistingBranch:
-		// Switch to existing branch
-		m.branchName = m.existingBranch
-		return m, m.switchBranchCmd
+	case StateConfirm, StateExistingBranch:
+		// The branch is already being fetched, created or switched to
+		if m.mutating || m.cancelOp != nil {
+			return m, nil
+		}
+		if m.state == StateExistingBranch {
+			m.existingRef = m.matches[m.matchCursor]
+		}
+		// Changes to tracked files need a decision before switching
+		if m.hasModifications() {
+			m.dirtyReturn = m.state
+			m.state = StateDirtyTree
+			return m, nil
+		}
+		return m.checkout(false)
 
 	case StateResult, StateError:
-		return m, tea.Quit
+		return m.quit()
 	}
 
 	return m, nil
@@ -0,0 +1,88 @@
+package tui
+
+import (
+	"context"
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/metalgrid/git-linear/internal/git"
+	"github.com/metalgrid/git-linear/internal/linear"
+)
+
+// fakeRepository records the branches created through it. Operations the
+// tests do not expect panic on the nil embedded Repository.
+type fakeRepository struct {
+	git.Repository
+	created []string
+}
+
+func (r *fakeRepository) GetStatus(ctx context.Context) (git.Status, error) {
+	return git.Status{}, nil
+}
+
+func (r *fakeRepository) GetDefaultBranch(ctx context.Context) (string, error) {
+	return "main", nil
+}
+
+func (r *fakeRepository) CreateBranch(ctx context.Context, name, base string) error {
+	r.created = append(r.created, name)
+	return nil
+}
+
+func (r *fakeRepository) RemoteExists(ctx context.Context, remote string) bool {
+	return false
+}
+
+func (r *fakeRepository) SwitchBranch(ctx context.Context, name string) error {
+	return nil
+}
+
+var _ = Describe("Update", func() {
+	var repo *fakeRepository
+
+	confirming := func(opts Options) Model {
+		repo = &fakeRepository{}
+		opts.Repository = repo
+		m := NewModel(context.Background(), linear.NewClient("key"), opts)
+		m.selectedIssue = &linear.Issue{Identifier: "DEV-1", Title: "Fix login"}
+		m.branchName = "dev-1-fix-login"
+		m.state = StateConfirm
+		return m
+	}
+
+	enter := func(m Model) (Model, tea.Cmd) {
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+		return updated.(Model), cmd
+	}
+
+	It("creates the branch once when enter is pressed twice", func() {
+		m, cmd := enter(confirming(Options{}))
+		Expect(cmd).NotTo(BeNil())
+
+		_, again := enter(m)
+		Expect(again).To(BeNil())
+
+		Expect(cmd()).To(BeAssignableToTypeOf(branchCreatedMsg{}))
+		Expect(repo.created).To(Equal([]string{"dev-1-fix-login"}))
+	})
+
+	It("ignores enter while the base is fetched", func() {
+		m, cmd := enter(confirming(Options{FetchBase: true}))
+		Expect(cmd).NotTo(BeNil())
+
+		_, again := enter(m)
+		Expect(again).To(BeNil())
+		Expect(repo.created).To(BeEmpty())
+	})
+
+	It("keeps earlier warnings when the issue cannot be started", func() {
+		m := confirming(Options{})
+		m.warningMsg = "⚠ Could not re-apply your changes"
+
+		updated, _ := m.Update(issueStartedMsg{err: errors.New("offline")})
+		Expect(updated.(Model).warningMsg).To(Equal("⚠ Could not re-apply your changes\n⚠ Could not update DEV-1: offline"))
+	})
+})
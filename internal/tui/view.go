@@ -2,15 +2,17 @@ package tui
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 )
 
 var (
-	titleStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("170"))
-	errorStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
-	resultStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
-	helpStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	titleStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("170"))
+	errorStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	resultStyle  = lipgloss.NewStyle().Foreground(lipgloss.Color("46"))
+	warningStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	helpStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
 )
 
 // View implements tea.Model
@@ -20,8 +22,11 @@ func (m Model) View() string {
 		return "Loading issues...\n"
 
 	case StateIssueList:
-		help := helpStyle.Render("\nj/k or ↑/↓: navigate • enter: select • q: quit")
-		return m.issueList.View() + help
+		help := "\nj/k or ↑/↓: navigate • enter: select • r: reload • q: quit"
+		if m.loadingMore {
+			help += " • loading more issues..."
+		}
+		return m.issueList.View() + helpStyle.Render(help)
 
 	case StateBranchEdit:
 		title := titleStyle.Render(fmt.Sprintf("Issue: %s - %s", m.selectedIssue.Identifier, m.selectedIssue.Title)) + "\n\n"
@@ -32,17 +37,68 @@ func (m Model) View() string {
 	case StateConfirm:
 		title := titleStyle.Render(fmt.Sprintf("Issue: %s - %s", m.selectedIssue.Identifier, m.selectedIssue.Title)) + "\n\n"
 		confirm := fmt.Sprintf("Create branch: %s\n\n", m.branchName)
-		help := helpStyle.Render("enter: create • esc: back")
+		if m.worktree {
+			confirm = fmt.Sprintf("Create worktree for branch: %s\n\n", m.branchName)
+		}
+		confirm += fmt.Sprintf("From: %s\n\n", m.baseLabel())
+		if m.pendingMsg != "" {
+			confirm += helpStyle.Render(m.pendingMsg) + "\n\n"
+		}
+		help := helpStyle.Render("enter: create • b: change base • esc: back")
 		return title + confirm + help
 
+	case StateBasePicker:
+		help := "\nj/k or ↑/↓: navigate • /: filter • enter: select • esc: back"
+		return m.basePicker.View() + helpStyle.Render(help)
+
 	case StateExistingBranch:
 		title := titleStyle.Render(fmt.Sprintf("Issue: %s - %s", m.selectedIssue.Identifier, m.selectedIssue.Title)) + "\n\n"
-		msg := fmt.Sprintf("Branch '%s' already exists.\n\n", m.existingBranch)
-		help := helpStyle.Render("enter: switch to existing branch • esc: back")
+		msg := fmt.Sprintf("Found existing branches for %s:\n\n", m.selectedIssue.Identifier)
+		for i, ref := range m.matches {
+			line := "  " + ref.String()
+			if i == m.matchCursor {
+				line = titleStyle.Render("> " + ref.String())
+			}
+			if !ref.Local() {
+				line += helpStyle.Render("  (remote only, creates a local tracking branch)")
+			}
+			msg += line + "\n"
+		}
+		help := helpStyle.Render("\nenter: switch to branch • n: create a new branch • esc: back")
+		return title + msg + help
+
+	case StateDirtyTree:
+		title := titleStyle.Render(fmt.Sprintf("Issue: %s - %s", m.selectedIssue.Identifier, m.selectedIssue.Title)) + "\n\n"
+		target := m.branchName
+		if m.dirtyReturn == StateExistingBranch {
+			target = m.existingRef.Branch
+		}
+		msg := fmt.Sprintf("You have uncommitted changes (%s).\n\n", m.status)
+		if !m.repo.KeepsChanges() {
+			msg += fmt.Sprintf("The git backend in use cannot take them to %s. Commit or stash them first.\n\n", target)
+			return title + msg + helpStyle.Render("a/esc: back")
+		}
+		msg += fmt.Sprintf("  s: stash them and re-apply them on %s\n", target)
+		msg += "  c: carry them over to the branch as they are\n"
+		msg += "  a: abort\n\n"
+		if m.status.Untracked > 0 {
+			msg += helpStyle.Render("Untracked files are carried over in both cases.") + "\n\n"
+		}
+		help := helpStyle.Render("enter: stash • c: carry over • esc: back")
 		return title + msg + help
 
 	case StateResult:
-		return resultStyle.Render(m.resultMsg) + "\n"
+		view := resultStyle.Render(m.resultMsg) + "\n"
+		if m.worktreePath != "" {
+			view += helpStyle.Render("  cd "+m.worktreePath) + "\n"
+		}
+		if m.pendingMsg != "" {
+			view += helpStyle.Render(m.pendingMsg) + "\n"
+		}
+		if m.warningMsg != "" {
+			view += warningStyle.Render(m.warningMsg) + "\n"
+		}
+		return view
 
 	case StateError:
 		return errorStyle.Render("Error: "+m.errorMsg) + "\n"
@@ -50,3 +106,25 @@ func (m Model) View() string {
 
 	return ""
 }
+
+// baseLabel describes the branch the new branch will be created from
+func (m Model) baseLabel() string {
+	switch {
+	case m.base != "":
+		return m.base + " (stacked)"
+	case m.fetchBase:
+		return fmt.Sprintf("%s/%s (fetched)", m.remote, m.defaultBranch)
+	}
+	return m.defaultBranch
+}
+
+// joinLines joins the non-empty messages into one message per line
+func joinLines(msgs ...string) string {
+	var lines []string
+	for _, msg := range msgs {
+		if msg != "" {
+			lines = append(lines, msg)
+		}
+	}
+	return strings.Join(lines, "\n")
+}